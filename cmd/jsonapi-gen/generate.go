@@ -0,0 +1,387 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// resourceField is a jsonapi tagged struct field, possibly promoted from an embedded struct.
+type resourceField struct {
+	path         string // the selector of the field from the struct, e.g. "ID" or "Base.ID"
+	directive    string // one of primary, attribute, relationship, meta or presence
+	resourceType string // only set for primary
+	memberName   string
+	exported     bool
+	omitEmpty    bool
+	typ          ast.Expr
+}
+
+// generator holds the struct types declared in the input files.
+type generator struct {
+	fset    *token.FileSet
+	pkg     string
+	structs map[string]*ast.TypeSpec
+	order   []string
+
+	// embedded holds the names of the structs embedded by other structs
+	embedded map[string]bool
+}
+
+// generate returns the formatted source of the methods generated for the given types declared
+// in files, or for every tagged struct declared in files if types is empty.
+func generate(files []string, types []string) ([]byte, error) {
+	g := &generator{fset: token.NewFileSet(), structs: make(map[string]*ast.TypeSpec), embedded: make(map[string]bool)}
+
+	for _, file := range files {
+		f, err := parser.ParseFile(g.fset, file, nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		if g.pkg == "" {
+			g.pkg = f.Name.Name
+		} else if g.pkg != f.Name.Name {
+			return nil, fmt.Errorf("input files belong to different packages %s and %s", g.pkg, f.Name.Name)
+		}
+		g.addStructs(f)
+	}
+
+	explicit := len(types) > 0
+	if !explicit {
+		types = g.order
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by jsonapi-gen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", g.pkg)
+	fmt.Fprintf(&buf, "import \"github.com/DataDog/jsonapi\"\n")
+
+	resources := make(map[string][]resourceField, len(types))
+	for _, name := range types {
+		ts, ok := g.structs[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s is not a struct declared in the input files", name)
+		}
+
+		fields, err := g.resourceFields(ts)
+		if err != nil {
+			return nil, err
+		}
+		resources[name] = fields
+	}
+
+	for _, name := range types {
+		fields := resources[name]
+		if !explicit && (len(fields) == 0 || g.embedded[name] && !hasPrimary(fields)) {
+			// not a jsonapi resource, or only fields shared by embedding resources
+			continue
+		}
+		if err := g.check(g.structs[name], fields); err != nil {
+			return nil, err
+		}
+
+		writeMarshal(&buf, name, fields)
+		writeUnmarshal(&buf, name, fields)
+	}
+
+	return format.Source(buf.Bytes())
+}
+
+func (g *generator) addStructs(f *ast.File) {
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if _, ok := ts.Type.(*ast.StructType); !ok || ts.Assign.IsValid() {
+				continue
+			}
+			g.structs[ts.Name.Name] = ts
+			g.order = append(g.order, ts.Name.Name)
+		}
+	}
+}
+
+// errorf returns an error prefixed with the position of the given node.
+func (g *generator) errorf(node ast.Node, format string, args ...any) error {
+	return fmt.Errorf("%s: %s", g.fset.Position(node.Pos()), fmt.Sprintf(format, args...))
+}
+
+// resourceFields returns the jsonapi tagged fields of ts, including the fields promoted from
+// untagged embedded structs, following the same rules as jsonapi.Marshal.
+func (g *generator) resourceFields(ts *ast.TypeSpec) ([]resourceField, error) {
+	return g.structFields(ts, "", map[string]bool{})
+}
+
+func (g *generator) structFields(ts *ast.TypeSpec, prefix string, seen map[string]bool) ([]resourceField, error) {
+	if seen[ts.Name.Name] {
+		return nil, g.errorf(ts, "%s embeds itself", ts.Name.Name)
+	}
+	seen[ts.Name.Name] = true
+	defer delete(seen, ts.Name.Name)
+
+	var (
+		fields     []resourceField
+		unresolved ast.Node
+	)
+	for _, f := range ts.Type.(*ast.StructType).Fields.List {
+		var tag reflect.StructTag
+		if f.Tag != nil {
+			s, err := strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, g.errorf(f.Tag, "invalid struct tag %s", f.Tag.Value)
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		names := make([]string, 0, len(f.Names))
+		for _, n := range f.Names {
+			names = append(names, n.Name)
+		}
+		if len(f.Names) == 0 {
+			names = append(names, embeddedName(f.Type))
+		}
+
+		jsonapiTag, ok := tag.Lookup("jsonapi")
+		if !ok || jsonapiTag == "" {
+			if len(f.Names) > 0 {
+				// this field is not tagged w/ jsonapi and will be ignored
+				continue
+			}
+
+			// untagged embedded structs have their fields promoted, like encoding/json does
+			et := f.Type
+			if star, ok := et.(*ast.StarExpr); ok {
+				if ident, ok := star.X.(*ast.Ident); ok && g.structs[ident.Name] != nil {
+					return nil, g.errorf(f, "embedded struct pointer *%s is not supported", ident.Name)
+				}
+			}
+			ident, ok := et.(*ast.Ident)
+			ets := g.structs[names[0]]
+			if !ok || ets == nil || ets.TypeParams != nil || ident.Name != names[0] {
+				// the embedded type may hold tagged fields that can't be seen from here
+				unresolved = f
+				continue
+			}
+			g.embedded[names[0]] = true
+			embedded, err := g.structFields(ets, prefix+names[0]+".", seen)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, embedded...)
+			continue
+		}
+
+		rf, err := parseTags(jsonapiTag, tag)
+		if err != nil {
+			return nil, g.errorf(f, "%s: %v", strings.Join(names, ", "), err)
+		}
+		for _, name := range names {
+			rf := rf
+			rf.path = prefix + name
+			rf.typ = f.Type
+			rf.exported = ast.IsExported(name)
+			if _, ok := tag.Lookup("json"); !ok && rf.exported {
+				rf.memberName = name
+			}
+			fields = append(fields, rf)
+		}
+	}
+
+	if len(fields) > 0 && unresolved != nil {
+		return nil, g.errorf(unresolved, "embedded field %s must be declared as a struct in the input files", embeddedName(unresolved.(*ast.Field).Type))
+	}
+
+	return fields, nil
+}
+
+// parseTags parses the jsonapi and json struct tags of a field.
+func parseTags(jsonapiTag string, tag reflect.StructTag) (resourceField, error) {
+	var rf resourceField
+
+	ts := strings.Split(jsonapiTag, ",")
+	if len(ts) > 3 {
+		return rf, fmt.Errorf("invalid jsonapi tag %q: expected format {directive},{optional:type},{optional:omitempty}", jsonapiTag)
+	}
+
+	switch ts[0] {
+	case "primary":
+		rf.directive = "primary"
+		if len(ts) < 2 {
+			return rf, fmt.Errorf("invalid jsonapi tag %q: missing type in primary directive", jsonapiTag)
+		}
+		rf.resourceType = ts[1]
+		rf.omitEmpty = len(ts) == 3 && ts[2] == "omitempty"
+		return rf, nil
+	case "attribute", "attr":
+		rf.directive = "attribute"
+	case "relationship", "rel":
+		rf.directive = "relationship"
+	case "meta":
+		rf.directive = "meta"
+	case "presence":
+		rf.directive = "presence"
+	default:
+		return rf, fmt.Errorf("invalid jsonapi tag %q: invalid directive", jsonapiTag)
+	}
+
+	if jsonTag, ok := tag.Lookup("json"); ok {
+		js := strings.Split(jsonTag, ",")
+		rf.memberName = js[0]
+		rf.omitEmpty = len(js) > 1 && js[1] == "omitempty"
+	}
+
+	return rf, nil
+}
+
+func hasPrimary(fields []resourceField) bool {
+	for _, f := range fields {
+		if f.directive == "primary" {
+			return true
+		}
+	}
+	return false
+}
+
+// check reports the tag mistakes that jsonapi.Marshal would otherwise only report at runtime.
+func (g *generator) check(ts *ast.TypeSpec, fields []resourceField) error {
+	if ts.TypeParams != nil {
+		return g.errorf(ts, "generic type %s is not supported", ts.Name.Name)
+	}
+
+	var primaries int
+	memberNames := make(map[string]string)
+	for _, f := range fields {
+		switch f.directive {
+		case "primary":
+			primaries++
+		case "attribute", "relationship":
+			if !f.exported {
+				continue
+			}
+			if other, ok := memberNames[f.memberName]; ok {
+				return g.errorf(ts, "%s: fields %s and %s share the member name %q", ts.Name.Name, other, f.path, f.memberName)
+			}
+			memberNames[f.memberName] = f.path
+		}
+	}
+
+	switch {
+	case primaries == 0:
+		return g.errorf(ts, "%s: primary/id field must labeled with `jsonapi:\"primary,{type}\"`", ts.Name.Name)
+	case primaries > 1:
+		return g.errorf(ts, "%s: there must be only one `jsonapi:\"primary\"` field", ts.Name.Name)
+	}
+
+	return nil
+}
+
+func writeMarshal(buf *bytes.Buffer, name string, fields []resourceField) {
+	fmt.Fprintf(buf, "\n// MarshalJSONAPIResource implements the jsonapi.ResourceMarshaler interface.\n")
+	fmt.Fprintf(buf, "func (v %s) MarshalJSONAPIResource(b *jsonapi.ResourceBuilder) error {\n", name)
+	for _, f := range fields {
+		switch f.directive {
+		case "primary":
+			fmt.Fprintf(buf, "if err := b.Primary(%q, v.%s); err != nil {\nreturn err\n}\n", f.resourceType, f.path)
+		case "attribute":
+			if !f.exported {
+				continue
+			}
+			switch cond := nonZero("v."+f.path, f.typ); {
+			case !f.omitEmpty:
+				fmt.Fprintf(buf, "b.Attribute(%q, v.%s)\n", f.memberName, f.path)
+			case cond != "":
+				fmt.Fprintf(buf, "if %s {\nb.Attribute(%q, v.%s)\n}\n", cond, f.memberName, f.path)
+			default:
+				fmt.Fprintf(buf, "b.AttributeOmitEmpty(%q, v.%s)\n", f.memberName, f.path)
+			}
+		case "relationship":
+			if !f.exported {
+				continue
+			}
+			switch cond := nonZero("v."+f.path, f.typ); {
+			case !f.omitEmpty:
+				fmt.Fprintf(buf, "if err := b.Relationship(%q, v.%s); err != nil {\nreturn err\n}\n", f.memberName, f.path)
+			case cond != "":
+				fmt.Fprintf(buf, "if %s {\nif err := b.Relationship(%q, v.%s); err != nil {\nreturn err\n}\n}\n", cond, f.memberName, f.path)
+			default:
+				fmt.Fprintf(buf, "if err := b.RelationshipOmitEmpty(%q, v.%s); err != nil {\nreturn err\n}\n", f.memberName, f.path)
+			}
+		case "meta":
+			fmt.Fprintf(buf, "if err := b.Meta(v.%s); err != nil {\nreturn err\n}\n", f.path)
+		}
+	}
+	fmt.Fprintf(buf, "return nil\n}\n")
+}
+
+func writeUnmarshal(buf *bytes.Buffer, name string, fields []resourceField) {
+	fmt.Fprintf(buf, "\n// UnmarshalJSONAPIResource implements the jsonapi.ResourceUnmarshaler interface.\n")
+	fmt.Fprintf(buf, "func (v *%s) UnmarshalJSONAPIResource(r *jsonapi.ResourceReader) error {\n", name)
+	for _, f := range fields {
+		switch f.directive {
+		case "primary":
+			fmt.Fprintf(buf, "if err := r.Primary(%q, %t, &v.%s); err != nil {\nreturn err\n}\n", f.resourceType, f.omitEmpty, f.path)
+		case "relationship":
+			if !f.exported {
+				continue
+			}
+			fmt.Fprintf(buf, "if err := r.Relationship(%q, &v.%s); err != nil {\nreturn err\n}\n", f.memberName, f.path)
+		case "meta":
+			fmt.Fprintf(buf, "if err := r.Meta(&v.%s); err != nil {\nreturn err\n}\n", f.path)
+		case "presence":
+			fmt.Fprintf(buf, "if err := r.Presence(&v.%s); err != nil {\nreturn err\n}\n", f.path)
+		}
+	}
+	fmt.Fprintf(buf, "return r.Attributes(v)\n}\n")
+}
+
+// nonZero returns a boolean expression reporting whether x of type typ is not its zero value,
+// or an empty string if that can't be determined from the syntax alone.
+func nonZero(x string, typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.StarExpr, *ast.MapType, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return x + " != nil"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return x + " != nil"
+		}
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return x + ` != ""`
+		case "bool":
+			return x
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "byte", "rune":
+			return x + " != 0"
+		case "any", "error":
+			return x + " != nil"
+		}
+	}
+	return ""
+}
+
+// embeddedName returns the field name of an embedded field of type typ.
+func embeddedName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	case *ast.IndexExpr:
+		return embeddedName(t.X)
+	case *ast.IndexListExpr:
+		return embeddedName(t.X)
+	}
+	return ""
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func writeSource(t *testing.T, src string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "models.go")
+	is.MustNoError(t, os.WriteFile(file, []byte(src), 0o600))
+
+	return file
+}
+
+const modelsSource = `package models
+
+type Author struct {
+	ID   string ` + "`" + `jsonapi:"primary,authors"` + "`" + `
+	Name string ` + "`" + `jsonapi:"attribute" json:"name"` + "`" + `
+}
+
+type Fields struct {
+	Body   string  ` + "`" + `jsonapi:"attribute" json:"body,omitempty"` + "`" + `
+	Author *Author ` + "`" + `jsonapi:"relationship" json:"author,omitempty"` + "`" + `
+}
+
+type Comment struct {
+	ID string ` + "`" + `jsonapi:"primary,comments,omitempty"` + "`" + `
+	Fields
+	Score   Score  ` + "`" + `jsonapi:"attribute" json:"score,omitempty"` + "`" + `
+	private string ` + "`" + `jsonapi:"attribute"` + "`" + `
+	Meta    any    ` + "`" + `jsonapi:"meta"` + "`" + `
+	Sent    map[string]bool ` + "`" + `jsonapi:"presence" json:"-"` + "`" + `
+}
+
+type Score int
+
+type Untagged struct {
+	Name string
+}
+`
+
+const modelsGenerated = `// Code generated by jsonapi-gen. DO NOT EDIT.
+
+package models
+
+import "github.com/DataDog/jsonapi"
+
+// MarshalJSONAPIResource implements the jsonapi.ResourceMarshaler interface.
+func (v Author) MarshalJSONAPIResource(b *jsonapi.ResourceBuilder) error {
+	if err := b.Primary("authors", v.ID); err != nil {
+		return err
+	}
+	b.Attribute("name", v.Name)
+	return nil
+}
+
+// UnmarshalJSONAPIResource implements the jsonapi.ResourceUnmarshaler interface.
+func (v *Author) UnmarshalJSONAPIResource(r *jsonapi.ResourceReader) error {
+	if err := r.Primary("authors", false, &v.ID); err != nil {
+		return err
+	}
+	return r.Attributes(v)
+}
+
+// MarshalJSONAPIResource implements the jsonapi.ResourceMarshaler interface.
+func (v Comment) MarshalJSONAPIResource(b *jsonapi.ResourceBuilder) error {
+	if err := b.Primary("comments", v.ID); err != nil {
+		return err
+	}
+	if v.Fields.Body != "" {
+		b.Attribute("body", v.Fields.Body)
+	}
+	if v.Fields.Author != nil {
+		if err := b.Relationship("author", v.Fields.Author); err != nil {
+			return err
+		}
+	}
+	b.AttributeOmitEmpty("score", v.Score)
+	if err := b.Meta(v.Meta); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalJSONAPIResource implements the jsonapi.ResourceUnmarshaler interface.
+func (v *Comment) UnmarshalJSONAPIResource(r *jsonapi.ResourceReader) error {
+	if err := r.Primary("comments", true, &v.ID); err != nil {
+		return err
+	}
+	if err := r.Relationship("author", &v.Fields.Author); err != nil {
+		return err
+	}
+	if err := r.Meta(&v.Meta); err != nil {
+		return err
+	}
+	if err := r.Presence(&v.Sent); err != nil {
+		return err
+	}
+	return r.Attributes(v)
+}
+`
+
+func TestGenerate(t *testing.T) {
+	t.Parallel()
+
+	out, err := generate([]string{writeSource(t, modelsSource)}, nil)
+	is.MustNoError(t, err)
+	is.Equal(t, modelsGenerated, string(out))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		types       []string
+		expect      string
+	}{
+		{
+			description: "invalid directive",
+			given:       "type A struct {\n\tID string `jsonapi:\"primary,a\"`\n\tName string `jsonapi:\"name\"`\n}",
+			expect:      "models.go:5:2: Name: invalid jsonapi tag \"name\": invalid directive",
+		}, {
+			description: "missing primary type",
+			given:       "type A struct {\n\tID string `jsonapi:\"primary\"`\n}",
+			expect:      "models.go:4:2: ID: invalid jsonapi tag \"primary\": missing type in primary directive",
+		}, {
+			description: "missing primary",
+			given:       "type A struct {\n\tName string `jsonapi:\"attribute\"`\n}",
+			expect:      "models.go:3:6: A: primary/id field must labeled with `jsonapi:\"primary,{type}\"`",
+		}, {
+			description: "duplicate primary",
+			given:       "type A struct {\n\tID, Key string `jsonapi:\"primary,a\"`\n}",
+			expect:      "models.go:3:6: A: there must be only one `jsonapi:\"primary\"` field",
+		}, {
+			description: "duplicate member name",
+			given:       "type A struct {\n\tID string `jsonapi:\"primary,a\"`\n\tB string `jsonapi:\"attr\" json:\"b\"`\n\tC string `jsonapi:\"rel\" json:\"b\"`\n}",
+			expect:      "models.go:3:6: A: fields B and C share the member name \"b\"",
+		}, {
+			description: "embedded struct pointer",
+			given:       "type A struct {\n\tID string `jsonapi:\"primary,a\"`\n\t*B\n}\ntype B struct {\n\tName string `jsonapi:\"attr\"`\n}",
+			expect:      "models.go:5:2: embedded struct pointer *B is not supported",
+		}, {
+			description: "embedded struct from another package",
+			given:       "type A struct {\n\tID string `jsonapi:\"primary,a\"`\n\tother.B\n}",
+			expect:      "models.go:5:2: embedded field B must be declared as a struct in the input files",
+		}, {
+			description: "unknown type",
+			given:       "type A struct{}",
+			types:       []string{"B"},
+			expect:      "type B is not a struct declared in the input files",
+		}, {
+			description: "explicit type without primary",
+			given:       "type A struct{}",
+			types:       []string{"A"},
+			expect:      "models.go:3:6: A: primary/id field must labeled with `jsonapi:\"primary,{type}\"`",
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.description, func(t *testing.T) {
+			t.Parallel()
+
+			file := writeSource(t, "package models\n\n"+tc.given+"\n")
+			_, err := generate([]string{file}, tc.types)
+			is.MustError(t, err)
+
+			msg := strings.TrimPrefix(err.Error(), filepath.Dir(file)+string(filepath.Separator))
+			is.Equal(t, tc.expect, msg)
+		})
+	}
+}
+
+func TestOutputName(t *testing.T) {
+	t.Parallel()
+
+	is.Equal(t, "models_jsonapi.go", outputName("models.go"))
+	is.Equal(t, "models_jsonapi_test.go", outputName("models_test.go"))
+}
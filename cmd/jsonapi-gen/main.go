@@ -0,0 +1,78 @@
+// Command jsonapi-gen generates jsonapi.ResourceMarshaler and jsonapi.ResourceUnmarshaler
+// implementations for structs with jsonapi struct tags, so that jsonapi.Marshal and
+// jsonapi.Unmarshal don't need to inspect those struct tags at runtime.
+//
+// It is meant to be run by go generate, next to the type declarations:
+//
+//	//go:generate go run github.com/DataDog/jsonapi/cmd/jsonapi-gen
+//
+// By default, methods are generated for every struct declared in $GOFILE with at least one
+// jsonapi struct tag, and are written to a file of the same name with a _jsonapi.go suffix
+// (or _jsonapi_test.go for test files).
+//
+// Usage:
+//
+//	jsonapi-gen [-type T1,T2] [-output file] [file.go ...]
+//
+// Struct tag mistakes, such as an invalid directive or a missing primary field, are reported
+// when generating instead of when marshaling. Untagged embedded structs are supported as long as
+// they are declared in one of the input files and are not pointers.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of type names; defaults to all tagged structs")
+		output    = flag.String("output", "", "output file name; defaults to <file>_jsonapi.go")
+	)
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: jsonapi-gen [-type T1,T2] [-output file] [file.go ...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		if gofile := os.Getenv("GOFILE"); gofile != "" {
+			files = []string{gofile}
+		}
+	}
+	if len(files) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	if *output == "" {
+		*output = outputName(files[0])
+	}
+
+	src, err := generate(files, types)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "jsonapi-gen: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "jsonapi-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// outputName returns the default output file name for the given input file.
+func outputName(file string) string {
+	if strings.HasSuffix(file, "_test.go") {
+		return strings.TrimSuffix(file, "_test.go") + "_jsonapi_test.go"
+	}
+	return strings.TrimSuffix(file, ".go") + "_jsonapi.go"
+}
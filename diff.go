@@ -0,0 +1,170 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// MarshalDiff returns the json:api encoding of after holding only the members which differ from
+// before, like the document of a PATCH request updating a resource loaded as before, as described
+// by https://jsonapi.org/format/1.0/#crud-updating. Both must be structs, or pointers to structs,
+// of the same type.
+//
+// Attributes are compared field by field, and changed ones are included even if they are empty
+// and tagged omitempty, so that they are cleared. Relationships are compared by the identifiers of
+// the resources they hold, and changed ones only include their resource linkage. Resource links are
+// left out, while document members like MarshalMeta are included as usual. Included resources are
+// left out once the relationships linking to them are, so that the document stays fully-linked.
+func MarshalDiff(before, after any, opts ...MarshalOption) ([]byte, error) {
+	return NewMarshaler(opts...).marshalDiff(before, after)
+}
+
+// MarshalDiff returns the json:api encoding of the members of after which differ from before like
+// the package-level MarshalDiff, using the options m was created with. The given options are
+// applied on top of those for this call only.
+func (m *Marshaler) MarshalDiff(before, after any, opts ...MarshalOption) ([]byte, error) {
+	return m.with(opts).marshalDiff(before, after)
+}
+
+// marshalDiff returns the json:api encoding of the members of after which differ from before using
+// the configuration of m.
+func (m *Marshaler) marshalDiff(before, after any) (b []byte, err error) {
+	defer func() {
+		// because we make use of reflect we must recover any panics
+		if rvr := recover(); rvr != nil {
+			err = recoverError(rvr)
+			return
+		}
+	}()
+
+	at := reflect.TypeOf(after)
+	if at == nil || schema.DerefType(at).Kind() != reflect.Struct {
+		return nil, &TypeError{Actual: fmt.Sprintf("%T", after), Expected: []string{"struct"}}
+	}
+	if bt := reflect.TypeOf(before); bt == nil || schema.DerefType(bt) != schema.DerefType(at) {
+		return nil, &TypeError{Actual: fmt.Sprintf("%T", before), Expected: []string{schema.DerefType(at).String()}}
+	}
+
+	var d *document
+	d, err = makeDocument(after, m, false)
+	if err != nil {
+		return
+	}
+	if d.DataOne != nil {
+		if err = d.diffResourceObject(d.DataOne, before, after, m); err != nil {
+			return
+		}
+		// included resources may only have been linked to by unchanged relationships
+		var ple *PartialLinkageError
+		if err = d.verifyFullLinkage(false); errors.As(err, &ple) {
+			d.Included = removeUnlinkedResourceObjects(d.Included, ple.invalidResources)
+		} else if err != nil {
+			return
+		}
+		// attributes and relationships may have been added back
+		filterDocumentFieldsets(d, m)
+	}
+
+	if err = d.validateMemberNames(m.memberNameValidationMode, ""); err != nil {
+		return
+	}
+
+	b, err = json.Marshal(d)
+
+	return
+}
+
+// diffResourceObject removes the members of ro, the resource object of after, whose fields are the
+// same in before, and adds the changed ones omitted because they are empty.
+func (d *document) diffResourceObject(ro *resourceObject, before, after any, m *Marshaler) error {
+	bv := derefValue(reflect.ValueOf(before))
+	av := derefValue(reflect.ValueOf(after))
+
+	fields, err := schema.CachedTypeFields(av.Type())
+	if err != nil {
+		return err
+	}
+
+	for i := range fields {
+		ft := &fields[i]
+		if ft.Tag.Directive == schema.Primary || ft.Tag.Directive == schema.Presence {
+			continue
+		}
+
+		bf, bok := schema.FieldByIndex(bv, ft.Index, false)
+		af, aok := schema.FieldByIndex(av, ft.Index, false)
+		var bi, ai any
+		if bok {
+			bi = bf.Interface()
+		}
+		if aok {
+			ai = af.Interface()
+		}
+
+		switch ft.Tag.Directive {
+		case schema.Attribute:
+			if !ft.Exported {
+				continue
+			}
+			if reflect.DeepEqual(bi, ai) || !aok || isAbsent(ai) {
+				delete(ro.Attributes, ft.MemberName)
+				continue
+			}
+			ro.Attributes[ft.MemberName] = ai
+		case schema.Relationship:
+			if !ft.Exported {
+				continue
+			}
+			if reflect.DeepEqual(linkageIdentifiers(bf, bok), linkageIdentifiers(af, aok)) {
+				delete(ro.Relationships, ft.MemberName)
+				continue
+			}
+			if err := d.marshalRelationship(after, ro, ft.MemberName, ai, m); err != nil {
+				return err
+			}
+			// only the resource linkage is updated
+			rd := ro.Relationships[ft.MemberName]
+			rd.Links = nil
+			rd.Meta = nil
+		case schema.Meta:
+			if reflect.DeepEqual(bi, ai) {
+				ro.Meta = nil
+			}
+		}
+	}
+
+	ro.Links = nil
+	return nil
+}
+
+// linkageIdentifiers returns the identifiers of the resources held by the relationship field fv, if
+// it is valid.
+func linkageIdentifiers(fv reflect.Value, ok bool) []string {
+	if !ok {
+		return nil
+	}
+
+	var identifiers []string
+	for _, rv := range relatedResources(fv) {
+		if rid, ok := existingIdentifier(rv); ok {
+			identifiers = append(identifiers, rid)
+		}
+	}
+	return identifiers
+}
+
+// removeUnlinkedResourceObjects returns the resource objects of ros whose identifiers aren't in
+// unlinked.
+func removeUnlinkedResourceObjects(ros []*resourceObject, unlinked []string) []*resourceObject {
+	var linked []*resourceObject
+	for _, ro := range ros {
+		if !containsString(unlinked, ro.getIdentifier()) {
+			linked = append(linked, ro)
+		}
+	}
+	return linked
+}
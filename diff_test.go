@@ -0,0 +1,134 @@
+package jsonapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestMarshalDiff(t *testing.T) {
+	t.Parallel()
+
+	before := ArticleRelated{
+		ID:       "1",
+		Title:    "A",
+		Author:   &Author{ID: "1", Name: "A"},
+		Comments: []*Comment{{ID: "1"}, {ID: "2"}},
+	}
+
+	tests := []struct {
+		description string
+		before      any
+		after       func(a ArticleRelated) any
+		opts        []MarshalOption
+		expect      string
+		expectError error
+	}{
+		{
+			description: "unchanged",
+			before:      &before,
+			after:       func(a ArticleRelated) any { return &a },
+			expect:      `{"data":{"id":"1","type":"articles"}}`,
+		}, {
+			description: "changed attribute",
+			before:      &before,
+			after: func(a ArticleRelated) any {
+				a.Title = "B"
+				return &a
+			},
+			expect: `{"data":{"id":"1","type":"articles","attributes":{"title":"B"}}}`,
+		}, {
+			description: "related resource changed without changing the linkage",
+			before:      &before,
+			after: func(a ArticleRelated) any {
+				a.Author = &Author{ID: "1", Name: "B"}
+				return a
+			},
+			expect: `{"data":{"id":"1","type":"articles"}}`,
+		}, {
+			description: "included resource of an unchanged relationship",
+			before:      &before,
+			after: func(a ArticleRelated) any {
+				a.Title = "B"
+				return &a
+			},
+			opts:   []MarshalOption{MarshalInclude(before.Author)},
+			expect: `{"data":{"id":"1","type":"articles","attributes":{"title":"B"}}}`,
+		}, {
+			description: "included resource of a changed relationship",
+			before:      &before,
+			after: func(a ArticleRelated) any {
+				a.Author = &Author{ID: "2", Name: "B"}
+				return &a
+			},
+			opts:   []MarshalOption{MarshalInclude(&Author{ID: "2", Name: "B"})},
+			expect: `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"2","type":"author"}}}},"included":[{"id":"2","type":"author","attributes":{"name":"B"}}]}`,
+		}, {
+			description: "changed relationships",
+			before:      before,
+			after: func(a ArticleRelated) any {
+				a.Author = &Author{ID: "2"}
+				a.Comments = []*Comment{{ID: "2"}, {ID: "1"}}
+				return &a
+			},
+			expect: `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"2","type":"author"}},"comments":{"data":[{"id":"2","type":"comments"},{"id":"1","type":"comments"}]}}}}`,
+		}, {
+			description: "cleared omitempty relationships",
+			before:      &before,
+			after: func(a ArticleRelated) any {
+				a.Author = nil
+				a.Comments = nil
+				return &a
+			},
+			expect: `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":null},"comments":{"data":[]}}}}`,
+		}, {
+			description: "cleared omitempty attribute",
+			before:      &Comment{ID: "1", Body: "A", Archived: true},
+			after:       func(ArticleRelated) any { return &Comment{ID: "1", Body: "A"} },
+			expect:      `{"data":{"id":"1","type":"comments","attributes":{"archived":false}}}`,
+		}, {
+			description: "optional attributes",
+			before:      &ArticlePatch{ID: "1"},
+			after: func(ArticleRelated) any {
+				return &ArticlePatch{ID: "1", Subtitle: Null[string]()}
+			},
+			expect: `{"data":{"id":"1","type":"articles","attributes":{"subtitle":null}}}`,
+		}, {
+			description: "document members",
+			before:      &before,
+			after: func(a ArticleRelated) any {
+				a.Title = "B"
+				return &a
+			},
+			opts:   []MarshalOption{MarshalMeta(map[string]any{"foo": "bar"})},
+			expect: `{"data":{"id":"1","type":"articles","attributes":{"title":"B"}},"meta":{"foo":"bar"}}`,
+		}, {
+			description: "different types",
+			before:      &articleA,
+			after:       func(a ArticleRelated) any { return &a },
+			expectError: &TypeError{Actual: "*jsonapi.Article", Expected: []string{"jsonapi.ArticleRelated"}},
+		}, {
+			description: "not a struct",
+			before:      &before,
+			after:       func(a ArticleRelated) any { return []*ArticleRelated{&a} },
+			expectError: &TypeError{Actual: "[]*jsonapi.ArticleRelated", Expected: []string{"struct"}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := MarshalDiff(tc.before, tc.after(before), tc.opts...)
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.EqualJSON(t, tc.expect, string(actual))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package jsonapi
+
+// Document is a json:api document as defined by https://jsonapi.org/format/1.0/#document-top-level,
+// whose primary data is of type T (e.g. *Article or []*Article).
+//
+// It gives typed access to every top-level member with a single call to MarshalDocument or
+// UnmarshalDocument, instead of options like MarshalMeta or UnmarshalMeta.
+type Document[T any] struct {
+	// Data is the primary data, which follows the same rules as the values given to Marshal and
+	// Unmarshal.
+	Data T
+
+	// Meta is the top-level meta object. When unmarshaling, its numbers are json.Number values.
+	Meta map[string]any
+
+	// Links is the top-level links object. When unmarshaling, its link objects are *LinkObject
+	// values.
+	Links *Link
+
+	// JSONAPI is the jsonapi object. Its version is always "1.0" when marshaling.
+	JSONAPI *JSONAPIObject
+
+	// Errors are the error objects of an error document, which must not have primary data.
+	Errors []*Error
+
+	// Included are the resources of a compound document. When marshaling, these are resources
+	// like the ones given to MarshalInclude. When unmarshaling, these are *Resource values, since
+	// their types aren't known. Included resources linked from the primary data are also
+	// unmarshaled into its relationship fields, like Unmarshal does.
+	Included []any
+}
+
+// MarshalDocument returns the json:api encoding of doc. The top-level members set in doc take
+// precedence over the given options.
+func MarshalDocument[T any](doc *Document[T], opts ...MarshalOption) ([]byte, error) {
+	m := NewMarshaler(opts...)
+
+	if doc.Meta != nil {
+		MarshalMeta(doc.Meta)(m)
+	}
+	if doc.Links != nil {
+		MarshalLinks(doc.Links)(m)
+	}
+	if doc.JSONAPI != nil {
+		MarshalJSONAPI(doc.JSONAPI.Meta)(m)
+	}
+	if len(doc.Included) > 0 {
+		MarshalInclude(doc.Included...)(m)
+	}
+
+	if len(doc.Errors) > 0 {
+		if !isZero(doc.Data) {
+			return nil, ErrDataAndErrors
+		}
+		return m.marshal(doc.Errors)
+	}
+
+	return m.marshal(doc.Data)
+}
+
+// UnmarshalDocument parses the json:api encoded data into a Document whose primary data is of
+// type T, see Unmarshal. The error objects of an error document are stored in Document.Errors
+// instead of being returned as a *DocumentError.
+func UnmarshalDocument[T any](data []byte, opts ...UnmarshalOption) (*Document[T], error) {
+	m := NewUnmarshaler(opts...)
+
+	d, err := m.parseDocument(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc Document[T]
+
+	// decode the included resource objects before unmarshaling aliases them into relationships
+	for _, ro := range d.Included {
+		r, err := newResource(ro)
+		if err != nil {
+			return nil, err
+		}
+		doc.Included = append(doc.Included, r)
+	}
+
+	if data, ok := any(&doc.Data).(*any); ok && !d.isErrorDocument() {
+		// without a Go type, the primary data is made of *Resource values
+		if err := m.unmarshalDynamic(d, data); err != nil {
+			return nil, err
+		}
+	} else {
+		var v any = &doc.Data
+		if d.isErrorDocument() {
+			v = &doc.Errors
+		}
+		if err := m.unmarshalDocument(d, v); err != nil {
+			return nil, err
+		}
+	}
+
+	meta, err := decodeGenericMeta(d.Meta)
+	if err != nil {
+		return nil, err
+	}
+	doc.Meta = meta
+	if doc.Links, err = decodeLink(d.Links); err != nil {
+		return nil, err
+	}
+	doc.JSONAPI = d.JSONAPI
+
+	return &doc, nil
+}
+
+// ParseDocument parses the json:api encoded data into a Document whose resources are described by
+// no Go types, for processing them dynamically (e.g. in a proxy). The primary data is either nil, a
+// *Resource or a []*Resource, and the included resources are *Resource values. The document goes
+// through the same validation as with Unmarshal.
+func ParseDocument(data []byte, opts ...UnmarshalOption) (*Document[any], error) {
+	return UnmarshalDocument[any](data, opts...)
+}
+
+// MarshalJSON implements the json.Marshaler interface, see MarshalDocument.
+func (doc Document[T]) MarshalJSON() ([]byte, error) {
+	return MarshalDocument(&doc)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface, see UnmarshalDocument.
+func (doc *Document[T]) UnmarshalJSON(data []byte) error {
+	parsed, err := UnmarshalDocument[T](data)
+	if err != nil {
+		return err
+	}
+	*doc = *parsed
+	return nil
+}
@@ -0,0 +1,67 @@
+package jsonapi_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DataDog/jsonapi"
+)
+
+func ExampleMarshalDocument() {
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	doc := jsonapi.Document[[]*Article]{
+		Data:  []*Article{{ID: "1", Title: "Hello World"}},
+		Meta:  map[string]any{"total": 1},
+		Links: &jsonapi.Link{Self: "http://example.com/articles"},
+	}
+
+	b, err := jsonapi.MarshalDocument(&doc)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s", string(b))
+	// Output: {"data":[{"id":"1","type":"articles","attributes":{"title":"Hello World"}}],"meta":{"total":1},"links":{"self":"http://example.com/articles"}}
+}
+
+func ExampleUnmarshalDocument() {
+	body := `{"data":[{"id":"1","type":"articles","attributes":{"title":"Hello World"}}],"meta":{"total":1},"links":{"next":"http://example.com/articles?page[number]=2"}}`
+
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	doc, err := jsonapi.UnmarshalDocument[[]*Article]([]byte(body))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%+v %v %s", *doc.Data[0], doc.Meta["total"], doc.Links.Next)
+	// Output: {ID:1 Title:Hello World} 1 http://example.com/articles?page[number]=2
+}
+
+func ExampleParseDocument() {
+	body := `{"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"},"relationships":{"author":{"data":{"id":"1","type":"people"}}}}}`
+
+	doc, err := jsonapi.ParseDocument([]byte(body))
+	if err != nil {
+		panic(err)
+	}
+
+	article := doc.Data.(*jsonapi.Resource)
+	article.SetAttribute("title", "Hello JSON:API")
+	article.DeleteRelationship("author")
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s", string(b))
+	// Output: {"data":{"id":"1","type":"articles","attributes":{"title":"Hello JSON:API"}}}
+}
@@ -0,0 +1,184 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestMarshalDocument(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		do          func() ([]byte, error)
+		expect      string
+		expectError error
+	}{
+		{
+			description: "primary data only",
+			do: func() ([]byte, error) {
+				return MarshalDocument(&Document[*Article]{Data: &articleA})
+			},
+			expect: articleABody,
+		}, {
+			description: "all top-level members",
+			do: func() ([]byte, error) {
+				return MarshalDocument(&Document[[]*ArticleRelated]{
+					Data:     articlesRelatedComplex,
+					Meta:     map[string]any{"meta_kind": "document-level meta"},
+					JSONAPI:  &JSONAPIObject{Meta: map[string]any{"meta_kind": "jsonapi meta"}},
+					Included: articlesRelatedComplexIncluded,
+				})
+			},
+			expect: articlesRelatedComplexBody,
+		}, {
+			description: "links",
+			do: func() ([]byte, error) {
+				return MarshalDocument(&Document[*Article]{
+					Data:  &articleA,
+					Links: &Link{Related: &LinkObject{Href: "http://example.com/article/1/comments", Meta: map[string]any{"foo": "bar"}}},
+				})
+			},
+			expect: articleAWithTopLevelLinksRelated,
+		}, {
+			description: "document members take precedence over options",
+			do: func() ([]byte, error) {
+				doc := Document[*Article]{Data: &articleA, Meta: map[string]any{"foo": "bar"}}
+				return MarshalDocument(&doc, MarshalMeta(map[string]any{"foo": "baz"}))
+			},
+			expect: articleAToplevelMetaBody,
+		}, {
+			description: "errors",
+			do: func() ([]byte, error) {
+				return MarshalDocument(&Document[*Article]{Errors: []*Error{&errorsSimpleStruct}})
+			},
+			expect: errorsSimpleStructBody,
+		}, {
+			description: "primary data and errors",
+			do: func() ([]byte, error) {
+				return MarshalDocument(&Document[*Article]{Data: &articleA, Errors: []*Error{&errorsSimpleStruct}})
+			},
+			expectError: ErrDataAndErrors,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := tc.do()
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.EqualJSON(t, tc.expect, string(actual))
+		})
+	}
+}
+
+func TestUnmarshalDocument(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		do          func() (any, error)
+		expect      any
+		expectError error
+	}{
+		{
+			description: "primary data only",
+			do: func() (any, error) {
+				return UnmarshalDocument[Article]([]byte(articleABody))
+			},
+			expect: &Document[Article]{Data: articleA},
+		}, {
+			description: "all top-level members",
+			do: func() (any, error) {
+				return UnmarshalDocument[[]*ArticleRelated]([]byte(articlesRelatedComplexBody))
+			},
+			expect: &Document[[]*ArticleRelated]{
+				Data:     articlesRelatedComplex,
+				Meta:     map[string]any{"meta_kind": "document-level meta"},
+				JSONAPI:  &JSONAPIObject{Version: "1.0", Meta: map[string]any{"meta_kind": "jsonapi meta"}},
+				Included: mustParseIncluded(articlesRelatedComplexBody),
+			},
+		}, {
+			description: "links",
+			do: func() (any, error) {
+				return UnmarshalDocument[*Article]([]byte(articleAWithTopLevelLinksRelated))
+			},
+			expect: &Document[*Article]{
+				Data:  &articleA,
+				Links: &Link{Related: &LinkObject{Href: "http://example.com/article/1/comments", Meta: map[string]any{"foo": "bar"}}},
+			},
+		}, {
+			description: "errors",
+			do: func() (any, error) {
+				return UnmarshalDocument[*Article]([]byte(errorsSimpleStructBody))
+			},
+			expect: &Document[*Article]{Errors: []*Error{&errorsSimpleStruct}},
+		}, {
+			description: "empty errors",
+			do: func() (any, error) {
+				return UnmarshalDocument[*Article]([]byte(`{"errors":[]}`))
+			},
+			expectError: &DocumentError{Errors: []*Error{}},
+		}, {
+			description: "empty errors (ParseDocument)",
+			do: func() (any, error) {
+				return ParseDocument([]byte(`{"errors":[]}`))
+			},
+			expectError: &DocumentError{Errors: []*Error{}},
+		}, {
+			description: "invalid type",
+			do: func() (any, error) {
+				return UnmarshalDocument[*Article]([]byte(articleAInvalidTypeBody))
+			},
+			expectError: &TypeError{Actual: "not-articles", Expected: []string{"articles"}},
+		}, {
+			description: "invalid member name",
+			do: func() (any, error) {
+				return UnmarshalDocument[*Author]([]byte(authorWithInvalidAttributeNameBody))
+			},
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/data/attributes/na%me"},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := tc.do()
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+// mustParseIncluded returns the included resources of the given document body.
+func mustParseIncluded(body string) []any {
+	var doc struct {
+		Included []*Resource `json:"included"`
+	}
+	if err := json.Unmarshal([]byte(body), &doc); err != nil {
+		panic(err)
+	}
+
+	included := make([]any, len(doc.Included))
+	for i, r := range doc.Included {
+		included[i] = r
+	}
+	return included
+}
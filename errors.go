@@ -7,11 +7,13 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/DataDog/jsonapi/internal/schema"
 )
 
 var (
 	// ErrMarshalInvalidPrimaryField indicates that the id (primary) fields was invalid.
-	ErrMarshalInvalidPrimaryField = errors.New("primary/id field must be a string or implement fmt.Stringer or in a struct which implements MarshalIdentifier")
+	ErrMarshalInvalidPrimaryField = schema.ErrInvalidPrimaryField
 
 	// ErrUnmarshalInvalidPrimaryField indicates that the id (primary) fields was invalid.
 	ErrUnmarshalInvalidPrimaryField = errors.New("primary/id field must be a string or in a struct which implements UnmarshalIdentifer")
@@ -41,7 +43,30 @@ var (
 	// the same type & id, or multiple resource linkages with the same type & id exist in a relationship section
 	ErrNonuniqueResource = errors.New("\"type\" and \"id\" must be unique across resources")
 
+	// ErrDocumentTooLarge indicates that a Decoder read more than its configured maximum number of bytes
+	ErrDocumentTooLarge = errors.New("document exceeds the maximum allowed size")
+
+	// ErrStreamClosed indicates that a StreamWriter was used after being closed
+	ErrStreamClosed = errors.New("stream writer is closed")
+
+	// ErrStreamIncludes indicates that included resources or include paths were given to
+	// StreamWriter.Close instead of the Encoder
+	ErrStreamIncludes = errors.New("the included resources of a stream must be given to the Encoder")
+
+	// ErrDecoderIterating indicates that Decoder.Decode was called before Decoder.Next finished reading a document
+	ErrDecoderIterating = errors.New("cannot decode a document while iterating over primary data with Next")
+
+	// ErrDataAndErrors indicates that a document was given both primary data and errors
+	ErrDataAndErrors = errors.New("the members \"data\" and \"errors\" must not coexist in a document")
+
+	// ErrInvalidPageSize indicates that a pagination strategy was given a default page size or limit
+	// less than 1, or greater than the maximum
+	ErrInvalidPageSize = errors.New("the default page size or limit must be at least 1 and at most the maximum")
+
 	// ErrErrorUnmarshalingNotImplemented indicates that an attempt was made to unmarshal an error document
+	//
+	// Deprecated: error documents are now unmarshaled into []*Error or *Error, or returned as a
+	// *DocumentError for any other target. This error is no longer returned.
 	ErrErrorUnmarshalingNotImplemented = errors.New("error unmarshaling is not implemented")
 )
 
@@ -59,17 +84,9 @@ func (e *TypeError) Error() string {
 	return fmt.Sprintf("got type %q expected %q", e.Actual, e.Expected[0])
 }
 
-// TagError indicates that an invalid struct tag was encountered.
-type TagError struct {
-	TagName string
-	Field   string
-	Reason  string
-}
-
-// Error implements the error interface.
-func (e *TagError) Error() string {
-	return fmt.Sprintf("invalid %q tag on field %q: %s", e.TagName, e.Field, e.Reason)
-}
+// TagError indicates that an invalid struct tag was encountered. Its TagName, Field and Reason
+// fields give the name of the tag, the name of the struct field and why the tag is invalid.
+type TagError = schema.TagError
 
 // PartialLinkageError indicates that an incomplete relationship chain was encountered.
 type PartialLinkageError struct {
@@ -86,13 +103,48 @@ func (e *PartialLinkageError) Error() string {
 }
 
 // MemberNameValidationError indicates that a document member name failed a validation step.
+//
+// Path was added after MemberName, so composite literals of MemberNameValidationError must use
+// field names (e.g. &MemberNameValidationError{MemberName: "na%me"}).
 type MemberNameValidationError struct {
 	MemberName string
+
+	// Path is the JSON pointer to the invalid member within the document, if known.
+	Path string
 }
 
 // Error implements the error interface.
 func (e *MemberNameValidationError) Error() string {
-	return fmt.Sprintf("invalid member name: %s", e.MemberName)
+	if e.Path == "" {
+		return fmt.Sprintf("invalid member name: %s", e.MemberName)
+	}
+	return fmt.Sprintf("invalid member name: %s at %s", e.MemberName, e.Path)
+}
+
+// DocumentError indicates that an error document was unmarshaled into a type other than []*Error
+// or *Error. It holds the decoded error objects and can be unwrapped to the first of them.
+type DocumentError struct {
+	Errors []*Error
+}
+
+// Error implements the error interface.
+func (e *DocumentError) Error() string {
+	if len(e.Errors) == 0 {
+		return "document contains an empty errors member"
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, eo := range e.Errors {
+		msgs[i] = eo.Error()
+	}
+	return fmt.Sprintf("document contains errors: %s", strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the first error object in the document, which allows errors.As to extract an *Error.
+func (e *DocumentError) Unwrap() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e.Errors[0]
 }
 
 // ErrorLink represents a JSON:API error links object as defined by https://jsonapi.org/format/1.1/#error-objects.
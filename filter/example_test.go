@@ -0,0 +1,60 @@
+package filter_test
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/DataDog/jsonapi"
+	"github.com/DataDog/jsonapi/filter"
+)
+
+type Author struct {
+	ID   string `jsonapi:"primary,author"`
+	Name string `jsonapi:"attribute" json:"name"`
+}
+
+type Comment struct {
+	ID   string `jsonapi:"primary,comments"`
+	Body string `jsonapi:"attribute" json:"body"`
+}
+
+type Article struct {
+	ID       string     `jsonapi:"primary,articles"`
+	Title    string     `jsonapi:"attribute" json:"title"`
+	Author   *Author    `jsonapi:"relationship" json:"author,omitempty"`
+	Comments []*Comment `jsonapi:"relationship" json:"comments,omitempty"`
+}
+
+func ExampleParse() {
+	query, _ := url.ParseQuery("filter=author.name eq 'Ana' or title in ('Go', 'Rust')&filter[comments][ne]=1")
+
+	q, err := jsonapi.ParseQuery(query)
+	if err != nil {
+		panic(err)
+	}
+	f, err := filter.Parse(q.Filter)
+	if err != nil {
+		panic(err)
+	}
+	if err := filter.Check(f, &Article{}); err != nil {
+		panic(err)
+	}
+
+	articles := []*Article{
+		{ID: "1", Title: "Go", Comments: []*Comment{{ID: "1"}}},
+		{ID: "2", Title: "C", Author: &Author{ID: "1", Name: "Ana"}},
+		{ID: "3", Title: "Rust"},
+	}
+	if err := filter.Apply(f, &articles); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(f)
+	for _, a := range articles {
+		fmt.Println(a.ID)
+	}
+	// Output:
+	// (author.name eq 'Ana' or title in ('Go', 'Rust')) and comments ne '1'
+	// 2
+	// 3
+}
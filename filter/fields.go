@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/DataDog/jsonapi"
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// queryError returns the error of the filter query parameter with the given name, like the ones of
+// jsonapi.ParseQuery.
+func queryError(name, format string, args ...any) *jsonapi.Error {
+	return jsonapi.QueryParameterError(name, fmt.Sprintf(format, args...))
+}
+
+// findField returns the exported attribute or relationship field of t with the given member name,
+// or nil. Its fields are the ones jsonapi.Marshal reads, so the error is a *jsonapi.TagError.
+func findField(t reflect.Type, name string) (*schema.Field, error) {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+
+	fields, err := schema.CachedTypeFields(t)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		f := &fields[i]
+		switch f.Tag.Directive {
+		case schema.Attribute, schema.Relationship:
+			if f.Exported && f.MemberName == name {
+				return f, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// relatedResources returns the non-nil structs held by the relationship field value rv.
+func relatedResources(rv reflect.Value) []reflect.Value {
+	switch rv.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return relatedResources(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		var resources []reflect.Value
+		for i := 0; i < rv.Len(); i++ {
+			resources = append(resources, relatedResources(rv.Index(i))...)
+		}
+		return resources
+	case reflect.Struct:
+		return []reflect.Value{rv}
+	}
+	return nil
+}
+
+// resourceID returns the id of the resource rv, a struct, like jsonapi.Marshal does.
+func resourceID(rv reflect.Value) (string, bool) {
+	fields, err := schema.CachedTypeFields(rv.Type())
+	if err != nil {
+		return "", false
+	}
+	for i := range fields {
+		f := &fields[i]
+		if f.Tag.Directive != schema.Primary {
+			continue
+		}
+		fv, ok := schema.FieldByIndex(rv, f.Index, false)
+		if !ok {
+			return "", false
+		}
+
+		// MarshalIdentifier may have a pointer receiver
+		v := rv.Interface()
+		if rv.CanAddr() {
+			v = rv.Addr().Interface()
+		}
+		id, err := schema.MarshalPrimary(v, fv.Interface())
+		return id, err == nil
+	}
+	return "", false
+}
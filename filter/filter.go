@@ -0,0 +1,526 @@
+// Package filter implements a filtering convention for the filter query parameters of json:api
+// requests, which the specification leaves to implementations (see
+// https://jsonapi.org/format/1.0/#fetching-filtering).
+//
+// Parse turns the filter parameters of a jsonapi.Query into an expression tree, whose fields are the
+// member names of the attributes and relationships of jsonapi tagged structs. Check validates an
+// expression against such a struct type, while Match and Apply evaluate it in memory.
+package filter
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/jsonapi"
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// Operator is the operator of a Condition.
+type Operator string
+
+const (
+	// Equal matches values equal to the value of the condition.
+	Equal Operator = "eq"
+
+	// NotEqual matches values not equal to the value of the condition.
+	NotEqual Operator = "ne"
+
+	// Less matches values less than the value of the condition.
+	Less Operator = "lt"
+
+	// LessOrEqual matches values less than or equal to the value of the condition.
+	LessOrEqual Operator = "le"
+
+	// Greater matches values greater than the value of the condition.
+	Greater Operator = "gt"
+
+	// GreaterOrEqual matches values greater than or equal to the value of the condition.
+	GreaterOrEqual Operator = "ge"
+
+	// In matches values equal to one of the values of the condition.
+	In Operator = "in"
+
+	// Contains matches strings containing the value of the condition, and slices holding it.
+	Contains Operator = "contains"
+)
+
+// isOperator returns true if op is one of the Operator constants.
+func isOperator(op string) bool {
+	switch Operator(op) {
+	case Equal, NotEqual, Less, LessOrEqual, Greater, GreaterOrEqual, In, Contains:
+		return true
+	default:
+		return false
+	}
+}
+
+// Expr is a node of a filter expression: an And, Or, Not or Condition. The String method returns
+// the expression in the syntax parsed by Parse.
+type Expr interface {
+	fmt.Stringer
+
+	// compile returns the function matching the resources of the struct type t.
+	compile(t reflect.Type) (matcher, error)
+}
+
+// matcher returns true if the resource rv, a struct, matches a filter expression.
+type matcher func(rv reflect.Value) bool
+
+// And matches resources matching all of its expressions.
+type And []Expr
+
+// Or matches resources matching any of its expressions.
+type Or []Expr
+
+// Not matches resources not matching its expression.
+type Not struct {
+	Expr Expr
+}
+
+// Condition matches resources whose field has values satisfying the operator. Field is the
+// member name of an attribute or relationship, optionally preceded by the member names of the
+// relationships leading to it (e.g. "author.name"). Relationships are compared by the ids of the
+// resources they hold, and only support the eq, ne and in operators.
+//
+// A condition matches if any of the values of the field satisfies it, except for the ne operator
+// which matches if none of them equals the value. Null values never satisfy the other operators.
+type Condition struct {
+	Field    string
+	Operator Operator
+
+	// Values holds the value of the condition, or the values of the in operator.
+	Values []string
+
+	// parameter is the name of the query parameter the condition was parsed from, for errors.
+	parameter string
+}
+
+// Parse parses the filter query parameters of a jsonapi.Query into a filter expression, combining
+// them with And. It returns nil if there are none. The following parameters are supported:
+//
+//   - filter[FIELD]=VALUE, a condition with the eq operator (e.g. filter[title]=Hello)
+//   - filter[FIELD][OPERATOR]=VALUE, a condition with the given operator, whose values are comma
+//     separated for the in operator (e.g. filter[priority][in]=1,2)
+//   - filter=EXPRESSION, a filter expression of conditions of the form FIELD OPERATOR VALUE joined
+//     with and, or, not and parentheses (e.g. title eq 'Hello' and not (priority in (1, 2)))
+//
+// Values in expressions may be quoted with single quotes, which are doubled to be escaped, and must be
+// when they contain whitespace, commas or parentheses. Malformed parameters are errors like the ones
+// of jsonapi.ParseQuery.
+func Parse(params []jsonapi.FilterParameter) (Expr, error) {
+	var exprs And
+	for _, param := range params {
+		expr, err := parseParameter(param)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	switch len(exprs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return exprs[0], nil
+	default:
+		return exprs, nil
+	}
+}
+
+// Check returns an error if the filter expression f doesn't apply to v, a struct or a pointer
+// to one, which is then a *jsonapi.Error like the ones of jsonapi.ParseQuery. Its fields must be attributes or
+// relationships of v, or of the resources related to it, and its values must be valid for them.
+// Servers must reject filters they don't support. Invalid jsonapi struct tags are a *jsonapi.TagError.
+func Check(f Expr, v any) error {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return &jsonapi.TypeError{Actual: "nil", Expected: []string{"struct"}}
+	}
+	if f == nil {
+		return nil
+	}
+	_, err := f.compile(schema.DerefType(t))
+	return err
+}
+
+// Match returns true if v, a struct or a pointer to one, matches the filter expression f. A nil
+// expression matches everything, and a nil pointer nothing. It returns the errors of Check.
+func Match(f Expr, v any) (bool, error) {
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() {
+		return false, &jsonapi.TypeError{Actual: "nil", Expected: []string{"struct"}}
+	}
+	if f == nil {
+		return true, nil
+	}
+
+	match, err := f.compile(schema.DerefType(rv.Type()))
+	if err != nil {
+		return false, err
+	}
+	return matchResource(match, rv), nil
+}
+
+// Apply removes the resources not matching the filter expression f from the slice pointed to by
+// v, keeping the order of the others. The elements of the slice must be structs or pointers to
+// structs, and nil pointers are removed. It returns the errors of Check.
+func Apply(f Expr, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.Elem().Kind() != reflect.Slice {
+		return &jsonapi.TypeError{Actual: fmt.Sprintf("%T", v), Expected: []string{"*slice"}}
+	}
+	if f == nil {
+		return nil
+	}
+
+	sv := rv.Elem()
+	match, err := f.compile(schema.DerefType(sv.Type().Elem()))
+	if err != nil {
+		return err
+	}
+
+	n := 0
+	for i := 0; i < sv.Len(); i++ {
+		if matchResource(match, sv.Index(i)) {
+			sv.Index(n).Set(sv.Index(i))
+			n++
+		}
+	}
+	for i := n; i < sv.Len(); i++ {
+		// don't keep the removed resources alive
+		sv.Index(i).Set(reflect.Zero(sv.Type().Elem()))
+	}
+	sv.SetLen(n)
+
+	return nil
+}
+
+// matchResource returns true if the resource rv, a struct or a pointer to one, matches.
+func matchResource(match matcher, rv reflect.Value) bool {
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return false
+		}
+		rv = rv.Elem()
+	}
+	return match(rv)
+}
+
+// String implements the fmt.Stringer interface.
+func (f And) String() string {
+	exprs := make([]string, len(f))
+	for i, expr := range f {
+		exprs[i] = expr.String()
+		if _, ok := expr.(Or); ok {
+			exprs[i] = "(" + exprs[i] + ")"
+		}
+	}
+	return strings.Join(exprs, " and ")
+}
+
+func (f And) compile(t reflect.Type) (matcher, error) {
+	matches, err := compileExprs(f, t)
+	if err != nil {
+		return nil, err
+	}
+	return func(rv reflect.Value) bool {
+		for _, match := range matches {
+			if !match(rv) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (f Or) String() string {
+	exprs := make([]string, len(f))
+	for i, expr := range f {
+		exprs[i] = expr.String()
+	}
+	return strings.Join(exprs, " or ")
+}
+
+func (f Or) compile(t reflect.Type) (matcher, error) {
+	matches, err := compileExprs(f, t)
+	if err != nil {
+		return nil, err
+	}
+	return func(rv reflect.Value) bool {
+		for _, match := range matches {
+			if match(rv) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// compileExprs returns the matchers of the given expressions.
+func compileExprs(exprs []Expr, t reflect.Type) ([]matcher, error) {
+	matches := make([]matcher, len(exprs))
+	for i, expr := range exprs {
+		match, err := expr.compile(t)
+		if err != nil {
+			return nil, err
+		}
+		matches[i] = match
+	}
+	return matches, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (f Not) String() string {
+	switch f.Expr.(type) {
+	case And, Or:
+		return "not (" + f.Expr.String() + ")"
+	default:
+		return "not " + f.Expr.String()
+	}
+}
+
+func (f Not) compile(t reflect.Type) (matcher, error) {
+	match, err := f.Expr.compile(t)
+	if err != nil {
+		return nil, err
+	}
+	return func(rv reflect.Value) bool {
+		return !match(rv)
+	}, nil
+}
+
+// String implements the fmt.Stringer interface.
+func (f Condition) String() string {
+	values := make([]string, len(f.Values))
+	for i, value := range f.Values {
+		values[i] = "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	}
+	if f.Operator == In {
+		return fmt.Sprintf("%s in (%s)", f.Field, strings.Join(values, ", "))
+	}
+	return fmt.Sprintf("%s %s %s", f.Field, f.Operator, strings.Join(values, ", "))
+}
+
+// queryParameter returns the name of the query parameter f was parsed from.
+func (f Condition) queryParameter() string {
+	if f.parameter == "" {
+		return "filter"
+	}
+	return f.parameter
+}
+
+func (f Condition) compile(t reflect.Type) (matcher, error) {
+	parameter := f.queryParameter()
+	if !isOperator(string(f.Operator)) {
+		return nil, queryError(parameter, "%q is not a filter operator", f.Operator)
+	}
+	if len(f.Values) == 0 || (f.Operator != In && len(f.Values) > 1) {
+		return nil, queryError(parameter, "the %q condition on %q must have one value", f.Operator, f.Field)
+	}
+
+	path, err := resolveFields(t, f.Field, parameter)
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+
+	var match func(fv reflect.Value) bool
+	if last.Tag.Directive == schema.Relationship {
+		match, err = f.compileRelationship()
+	} else {
+		match, err = f.compileAttribute(schema.DerefType(last.Type))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return func(rv reflect.Value) bool {
+		resources := []reflect.Value{rv}
+		for _, ft := range path[:len(path)-1] {
+			var related []reflect.Value
+			for _, r := range resources {
+				if fv, ok := schema.FieldByIndex(r, ft.Index, false); ok {
+					related = append(related, relatedResources(fv)...)
+				}
+			}
+			for i := range related {
+				related[i] = reflect.Indirect(related[i])
+			}
+			resources = related
+		}
+
+		// ne matches if no value is equal, rather than if any value isn't
+		matched := false
+		for _, r := range resources {
+			if fv, ok := schema.FieldByIndex(r, last.Index, false); ok && match(fv) {
+				matched = true
+				break
+			}
+		}
+		return matched != (f.Operator == NotEqual)
+	}, nil
+}
+
+// compileRelationship returns the function matching the values of a relationship field.
+func (f Condition) compileRelationship() (func(fv reflect.Value) bool, error) {
+	switch f.Operator {
+	case Equal, NotEqual, In:
+	default:
+		return nil, queryError(f.queryParameter(), "%q does not support the %q operator", f.Field, f.Operator)
+	}
+
+	ids := make(map[string]bool, len(f.Values))
+	for _, value := range f.Values {
+		ids[value] = true
+	}
+	return func(fv reflect.Value) bool {
+		for _, r := range relatedResources(fv) {
+			if id, ok := resourceID(r); ok && ids[id] {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// compileAttribute returns the function matching the values of an attribute field of type t.
+func (f Condition) compileAttribute(t reflect.Type) (func(fv reflect.Value) bool, error) {
+	vt := t
+	switch {
+	case f.Operator == Contains && t.Kind() == reflect.Slice:
+		vt = schema.DerefType(t.Elem())
+		if !schema.IsComparable(vt) {
+			return nil, queryError(f.queryParameter(), "%q does not support the %q operator", f.Field, f.Operator)
+		}
+	case f.Operator == Contains && t.Kind() != reflect.String,
+		!schema.IsComparable(t),
+		t.Kind() == reflect.Bool && f.Operator != Equal && f.Operator != NotEqual && f.Operator != In:
+		return nil, queryError(f.queryParameter(), "%q does not support the %q operator", f.Field, f.Operator)
+	}
+
+	values := make([]reflect.Value, len(f.Values))
+	for i, value := range f.Values {
+		v, err := parseFieldValue(vt, value)
+		if err != nil {
+			return nil, queryError(f.queryParameter(), "%q is not a valid value of %q", value, f.Field)
+		}
+		values[i] = v
+	}
+
+	return func(fv reflect.Value) bool {
+		for fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				// null values only match ne
+				return false
+			}
+			fv = fv.Elem()
+		}
+
+		switch f.Operator {
+		case Contains:
+			if fv.Kind() == reflect.String {
+				return strings.Contains(fv.String(), values[0].String())
+			}
+			for i := 0; i < fv.Len(); i++ {
+				ev := reflect.Indirect(fv.Index(i))
+				if ev.IsValid() && schema.Compare(ev, values[0]) == 0 {
+					return true
+				}
+			}
+			return false
+		case In:
+			for _, v := range values {
+				if schema.Compare(fv, v) == 0 {
+					return true
+				}
+			}
+			return false
+		}
+
+		c := schema.Compare(fv, values[0])
+		switch f.Operator {
+		case Less:
+			return c < 0
+		case LessOrEqual:
+			return c <= 0
+		case Greater:
+			return c > 0
+		case GreaterOrEqual:
+			return c >= 0
+		default:
+			// eq, and ne which is negated by the caller
+			return c == 0
+		}
+	}, nil
+}
+
+// resolveFields returns the fields of the struct type t along the member names of the given field
+// path: relationships followed by an attribute or relationship.
+func resolveFields(t reflect.Type, path, parameter string) ([]*schema.Field, error) {
+	var fields []*schema.Field
+	names := strings.Split(path, ".")
+	for i, name := range names {
+		f, err := findField(t, name)
+		if err != nil {
+			return nil, err
+		}
+		if f == nil || (i < len(names)-1 && f.Tag.Directive != schema.Relationship) {
+			return nil, queryError(parameter, "%q is not a filterable field", path)
+		}
+		fields = append(fields, f)
+
+		// the resources of a relationship are structs, pointers to structs, or slices of them
+		t = schema.DerefType(f.Type)
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = schema.DerefType(t.Elem())
+		}
+	}
+	return fields, nil
+}
+
+// parseFieldValue returns the value of the comparable type t held by s. Times are in RFC 3339 format.
+func parseFieldValue(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if t == reflect.TypeOf(time.Time{}) {
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.ValueOf(tm))
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 10, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(n)
+	default:
+		v.SetString(s)
+	}
+	return v, nil
+}
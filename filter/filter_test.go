@@ -0,0 +1,320 @@
+package filter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataDog/jsonapi"
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+type Author struct {
+	ID   string `jsonapi:"primary,author"`
+	Name string `jsonapi:"attribute" json:"name"`
+}
+
+type Event struct {
+	ID       string     `jsonapi:"primary,events"`
+	Name     string     `jsonapi:"attribute" json:"name"`
+	Priority int        `jsonapi:"attribute" json:"priority"`
+	Done     bool       `jsonapi:"attribute" json:"done"`
+	Start    *time.Time `jsonapi:"attribute" json:"start"`
+	Tags     []string   `jsonapi:"attribute" json:"tags"`
+	Author   *Author    `jsonapi:"relationship" json:"author"`
+}
+
+type Article struct {
+	ID    string `jsonapi:"primary,articles"`
+	Title string `jsonapi:"attribute" json:"title"`
+	Note  string `jsonapi:"attribute" json:",omitempty"`
+}
+
+// Draft has an invalid jsonapi tag.
+type Draft struct {
+	ID    string `jsonapi:"primary"`
+	Title string `jsonapi:"attribute" json:"title"`
+}
+
+// Node embeds itself, which must not recurse forever when its fields are computed.
+type Node struct {
+	ID   string `jsonapi:"primary,nodes"`
+	Name string `jsonapi:"attribute" json:"name"`
+	*Node
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       []jsonapi.FilterParameter
+		expect      Expr
+		expectError error
+	}{
+		{
+			description: "none",
+			given:       nil,
+			expect:      nil,
+		}, {
+			description: "field",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"name"}, Value: "a"}},
+			expect:      Condition{Field: "name", Operator: Equal, Values: []string{"a"}, parameter: "filter[name]"},
+		}, {
+			description: "fields with operators",
+			given: []jsonapi.FilterParameter{
+				{Keys: []string{"priority", "in"}, Value: "1,2"},
+				{Keys: []string{"author.name", "ne"}, Value: "b"},
+			},
+			expect: And{
+				Condition{Field: "priority", Operator: In, Values: []string{"1", "2"}, parameter: "filter[priority][in]"},
+				Condition{Field: "author.name", Operator: NotEqual, Values: []string{"b"}, parameter: "filter[author.name][ne]"},
+			},
+		}, {
+			description: "expression precedence",
+			given:       []jsonapi.FilterParameter{{Value: "name eq a or not done eq true and priority gt 1"}},
+			expect: Or{
+				Condition{Field: "name", Operator: Equal, Values: []string{"a"}, parameter: "filter"},
+				And{
+					Not{Expr: Condition{Field: "done", Operator: Equal, Values: []string{"true"}, parameter: "filter"}},
+					Condition{Field: "priority", Operator: Greater, Values: []string{"1"}, parameter: "filter"},
+				},
+			},
+		}, {
+			description: "expression with parentheses and quoted values",
+			given:       []jsonapi.FilterParameter{{Value: "(name in ('a, b', 'it''s') or tags contains x) and author eq '1'"}},
+			expect: And{
+				Or{
+					Condition{Field: "name", Operator: In, Values: []string{"a, b", "it's"}, parameter: "filter"},
+					Condition{Field: "tags", Operator: Contains, Values: []string{"x"}, parameter: "filter"},
+				},
+				Condition{Field: "author", Operator: Equal, Values: []string{"1"}, parameter: "filter"},
+			},
+		}, {
+			description: "unknown operator",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"name", "like"}, Value: "a"}},
+			expectError: queryError("filter[name][like]", `"like" is not a filter operator`),
+		}, {
+			description: "too many keys",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"author", "name", "eq"}, Value: "a"}},
+			expectError: queryError("filter[author][name][eq]", `"filter[author][name][eq]" must be of the form filter[FIELD] or filter[FIELD][OPERATOR]`),
+		}, {
+			description: "empty field name",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"author."}, Value: "a"}},
+			expectError: queryError("filter[author.]", `"author." has an empty field name`),
+		}, {
+			description: "missing value",
+			given:       []jsonapi.FilterParameter{{Value: "name eq"}},
+			expectError: queryError("filter", `"name eq" is not a valid filter expression: unexpected end`),
+		}, {
+			description: "unbalanced parentheses",
+			given:       []jsonapi.FilterParameter{{Value: "(name eq a"}},
+			expectError: queryError("filter", `"(name eq a" is not a valid filter expression: unexpected end`),
+		}, {
+			description: "trailing token",
+			given:       []jsonapi.FilterParameter{{Value: "name eq a b"}},
+			expectError: queryError("filter", `"name eq a b" is not a valid filter expression: unexpected "b"`),
+		}, {
+			description: "unterminated quoted value",
+			given:       []jsonapi.FilterParameter{{Value: "name eq 'a"}},
+			expectError: queryError("filter", `"name eq 'a" is not a valid filter expression: unterminated quoted value`),
+		}, {
+			description: "unknown operator in expression",
+			given:       []jsonapi.FilterParameter{{Value: "name is a"}},
+			expectError: queryError("filter", `"name is a" is not a valid filter expression: "is" is not a filter operator`),
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := Parse(tc.given)
+			if tc.expectError != nil {
+				is.Equal(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+
+			if actual != nil {
+				// the string of an expression parses into the same expression
+				again, err := Parse([]jsonapi.FilterParameter{{Value: actual.String()}})
+				is.MustNoError(t, err)
+				is.Equal(t, actual.String(), again.String())
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		expectError error
+	}{
+		{
+			description: "attributes and relationships",
+			given:       "name eq a and priority ge 2 and done ne false and start lt '2022-01-02T00:00:00Z' and tags contains x and author in (1, 2) and author.name contains b",
+		}, {
+			description: "unknown field",
+			given:       "title eq a",
+			expectError: queryError("filter", `"title" is not a filterable field`),
+		}, {
+			description: "attribute of an attribute",
+			given:       "name.length eq 1",
+			expectError: queryError("filter", `"name.length" is not a filterable field`),
+		}, {
+			description: "unknown field of a relationship",
+			given:       "author.title eq a",
+			expectError: queryError("filter", `"author.title" is not a filterable field`),
+		}, {
+			description: "invalid number",
+			given:       "priority eq high",
+			expectError: queryError("filter", `"high" is not a valid value of "priority"`),
+		}, {
+			description: "invalid time",
+			given:       "start gt yesterday",
+			expectError: queryError("filter", `"yesterday" is not a valid value of "start"`),
+		}, {
+			description: "ordered bool",
+			given:       "done gt false",
+			expectError: queryError("filter", `"done" does not support the "gt" operator`),
+		}, {
+			description: "ordered relationship",
+			given:       "author lt 2",
+			expectError: queryError("filter", `"author" does not support the "lt" operator`),
+		}, {
+			description: "contains number",
+			given:       "priority contains 1",
+			expectError: queryError("filter", `"priority" does not support the "contains" operator`),
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			f, err := Parse([]jsonapi.FilterParameter{{Value: tc.given}})
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expectError, Check(f, &Event{}))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) *time.Time {
+		t := time.Date(2022, 1, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	events := []*Event{
+		{ID: "1", Name: "b", Priority: 1, Start: day(2), Tags: []string{"x"}, Author: &Author{ID: "1", Name: "alice"}},
+		{ID: "2", Name: "a", Priority: 2, Done: true, Author: &Author{ID: "2", Name: "bob"}},
+		{ID: "3", Name: "c", Priority: 1, Start: day(1), Tags: []string{"x", "y"}},
+		{ID: "4", Name: "a", Priority: 3, Start: day(3), Author: &Author{ID: "1", Name: "alice"}},
+	}
+
+	tests := []struct {
+		description string
+		given       []jsonapi.FilterParameter
+		expect      []string
+	}{
+		{
+			description: "equal",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"name"}, Value: "a"}},
+			expect:      []string{"2", "4"},
+		}, {
+			description: "greater and in",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"priority", "gt"}, Value: "1"}, {Keys: []string{"name", "in"}, Value: "a,b"}},
+			expect:      []string{"2", "4"},
+		}, {
+			description: "time with null",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"start", "le"}, Value: "2022-01-02T00:00:00Z"}},
+			expect:      []string{"1", "3"},
+		}, {
+			description: "not equal to null",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"start", "ne"}, Value: "2022-01-02T00:00:00Z"}},
+			expect:      []string{"2", "3", "4"},
+		}, {
+			description: "contains",
+			given:       []jsonapi.FilterParameter{{Value: "tags contains y or name contains b"}},
+			expect:      []string{"1", "3"},
+		}, {
+			description: "relationship",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"author"}, Value: "1"}},
+			expect:      []string{"1", "4"},
+		}, {
+			description: "relationship not equal",
+			given:       []jsonapi.FilterParameter{{Keys: []string{"author", "ne"}, Value: "1"}},
+			expect:      []string{"2", "3"},
+		}, {
+			description: "related attribute",
+			given:       []jsonapi.FilterParameter{{Value: "not (author.name eq alice) and done eq false"}},
+			expect:      []string{"3"},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			f, err := Parse(tc.given)
+			is.MustNoError(t, err)
+
+			// both slices of pointers and of structs are filtered
+			filtered := append([]*Event(nil), events...)
+			values := make([]Event, len(events))
+			for i, e := range events {
+				values[i] = *e
+			}
+			is.MustNoError(t, Apply(f, &filtered))
+			is.MustNoError(t, Apply(f, &values))
+
+			is.Equal(t, len(tc.expect), len(filtered))
+			is.Equal(t, len(tc.expect), len(values))
+			for i, id := range tc.expect {
+				is.Equal(t, id, filtered[i].ID)
+				is.Equal(t, id, values[i].ID)
+			}
+
+			match, err := Match(f, events[len(events)-1])
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect[len(tc.expect)-1] == "4", match)
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	t.Parallel()
+
+	f := Condition{Field: "name", Operator: Equal, Values: []string{"a"}}
+
+	is.EqualError(t, &jsonapi.TypeError{Actual: "[]*filter.Event", Expected: []string{"*slice"}}, Apply(f, []*Event{}))
+	is.Equal(t, queryError("filter", `"name" is not a filterable field`), Apply(f, &[]*Article{}))
+
+	// the fields are the ones of jsonapi.Marshal, which names the attributes after their json tags
+	note := Condition{Field: "Note", Operator: Equal, Values: []string{"a"}}
+	is.Equal(t, queryError("filter", `"Note" is not a filterable field`), Check(note, &Article{}))
+	is.Equal(t, &jsonapi.TagError{TagName: "jsonapi", Field: "ID", Reason: "missing type in primary directive"}, Check(f, &Draft{}))
+}
+
+func TestMatchRecursiveEmbedding(t *testing.T) {
+	t.Parallel()
+
+	f, err := Parse([]jsonapi.FilterParameter{{Keys: []string{"name"}, Value: "a"}})
+	is.MustNoError(t, err)
+
+	match, err := Match(f, &Node{ID: "1", Name: "a"})
+	is.MustNoError(t, err)
+	is.Equal(t, true, match)
+}
@@ -0,0 +1,279 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DataDog/jsonapi"
+)
+
+// parseParameter parses a filter query parameter into a filter expression.
+func parseParameter(param jsonapi.FilterParameter) (Expr, error) {
+	name := "filter"
+	for _, key := range param.Keys {
+		name += "[" + key + "]"
+	}
+
+	switch len(param.Keys) {
+	case 0:
+		return parseExpression(name, param.Value)
+	case 1, 2:
+		f := Condition{Field: param.Keys[0], Operator: Equal, Values: []string{param.Value}, parameter: name}
+		if len(param.Keys) == 2 {
+			f.Operator = Operator(param.Keys[1])
+		}
+		if !isOperator(string(f.Operator)) {
+			return nil, queryError(name, "%q is not a filter operator", f.Operator)
+		}
+		if f.Operator == In {
+			f.Values = strings.Split(param.Value, ",")
+		}
+		if !isFieldPath(f.Field) {
+			return nil, queryError(name, "%q has an empty field name", f.Field)
+		}
+		return f, nil
+	default:
+		return nil, queryError(name, "%q must be of the form filter[FIELD] or filter[FIELD][OPERATOR]", name)
+	}
+}
+
+// isFieldPath returns true if none of the member names of the field path is empty.
+func isFieldPath(path string) bool {
+	for _, name := range strings.Split(path, ".") {
+		if name == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// token is a token of a filter expression: a punctuation character, a word, or a quoted value.
+type token struct {
+	text   string
+	quoted bool
+}
+
+// is returns true if t is the given unquoted word or punctuation character.
+func (t token) is(text string) bool {
+	return !t.quoted && t.text == text
+}
+
+// isWord returns true if t is an unquoted word, rather than punctuation.
+func (t token) isWord() bool {
+	return !t.quoted && !strings.ContainsAny(t.text, "(),")
+}
+
+// parser parses a filter expression given by the query parameter with the given name.
+type parser struct {
+	parameter  string
+	expression string
+	tokens     []token
+	pos        int
+}
+
+// parseExpression parses the filter expression of the query parameter with the given name.
+func parseExpression(name, expression string) (Expr, error) {
+	p := &parser{parameter: name, expression: expression}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.unexpected()
+	}
+	return expr, nil
+}
+
+// errorf returns the error of the filter expression of p.
+func (p *parser) errorf(format string, args ...any) *jsonapi.Error {
+	return queryError(p.parameter, "%q is not a valid filter expression: %s", p.expression, fmt.Sprintf(format, args...))
+}
+
+// unexpected returns the error of the current token of p.
+func (p *parser) unexpected() *jsonapi.Error {
+	if p.pos >= len(p.tokens) {
+		return p.errorf("unexpected end")
+	}
+	return p.errorf("unexpected %q", p.tokens[p.pos].text)
+}
+
+// tokenize splits the expression of p into tokens.
+func (p *parser) tokenize() error {
+	s := p.expression
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(' || c == ')' || c == ',':
+			p.tokens = append(p.tokens, token{text: string(c)})
+			i++
+		case c == '\'':
+			var b strings.Builder
+			for i++; ; i++ {
+				if i >= len(s) {
+					return p.errorf("unterminated quoted value")
+				}
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						// a doubled quote is an escaped quote
+						i++
+					} else {
+						break
+					}
+				}
+				b.WriteByte(s[i])
+			}
+			p.tokens = append(p.tokens, token{text: b.String(), quoted: true})
+			i++
+		default:
+			j := i
+			for j < len(s) && !strings.ContainsRune(" \t\n\r(),'", rune(s[j])) {
+				j++
+			}
+			p.tokens = append(p.tokens, token{text: s[i:j]})
+			i = j
+		}
+	}
+	return nil
+}
+
+// peek returns true if the current token of p is the given unquoted word or punctuation character.
+func (p *parser) peek(text string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].is(text)
+}
+
+// expect consumes the current token of p, which must be the given punctuation character.
+func (p *parser) expect(text string) error {
+	if !p.peek(text) {
+		return p.unexpected()
+	}
+	p.pos++
+	return nil
+}
+
+// parseOr parses expressions joined with or.
+func (p *parser) parseOr() (Expr, error) {
+	var exprs Or
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.peek("or") {
+			break
+		}
+		p.pos++
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+// parseAnd parses expressions joined with and.
+func (p *parser) parseAnd() (Expr, error) {
+	var exprs And
+	for {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		if !p.peek("and") {
+			break
+		}
+		p.pos++
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return exprs, nil
+}
+
+// parseUnary parses a negated expression, an expression in parentheses, or a condition.
+func (p *parser) parseUnary() (Expr, error) {
+	switch {
+	case p.peek("not"):
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: expr}, nil
+	case p.peek("("):
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		return p.parseCondition()
+	}
+}
+
+// parseCondition parses a condition of the form FIELD OPERATOR VALUE, or FIELD in (VALUE, ...).
+func (p *parser) parseCondition() (Expr, error) {
+	if p.pos+1 >= len(p.tokens) || !p.tokens[p.pos].isWord() || !p.tokens[p.pos+1].isWord() {
+		return nil, p.unexpected()
+	}
+	f := Condition{
+		Field:     p.tokens[p.pos].text,
+		Operator:  Operator(p.tokens[p.pos+1].text),
+		parameter: p.parameter,
+	}
+	if !isFieldPath(f.Field) {
+		return nil, p.errorf("%q has an empty field name", f.Field)
+	}
+	if !isOperator(string(f.Operator)) {
+		return nil, p.errorf("%q is not a filter operator", f.Operator)
+	}
+	p.pos += 2
+
+	if f.Operator != In {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		f.Values = []string{value}
+		return f, nil
+	}
+
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		f.Values = append(f.Values, value)
+		if !p.peek(",") {
+			break
+		}
+		p.pos++
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// parseValue parses a quoted value or a word.
+func (p *parser) parseValue() (string, error) {
+	if p.pos >= len(p.tokens) || !(p.tokens[p.pos].quoted || p.tokens[p.pos].isWord()) {
+		return "", p.unexpected()
+	}
+	p.pos++
+	return p.tokens[p.pos-1].text, nil
+}
@@ -0,0 +1,154 @@
+package jsonapi
+
+import (
+	"reflect"
+
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// ResourceMarshaler is implemented by types whose resource objects are built without inspecting
+// their jsonapi struct tags at runtime. Implementations are normally generated by cmd/jsonapi-gen.
+//
+// Marshal uses MarshalJSONAPIResource instead of reflection whenever a resource implements it.
+type ResourceMarshaler interface {
+	MarshalJSONAPIResource(b *ResourceBuilder) error
+}
+
+// ResourceUnmarshaler is implemented by types that consume resource objects without inspecting
+// their jsonapi struct tags at runtime. Implementations are normally generated by cmd/jsonapi-gen.
+//
+// Unmarshal uses UnmarshalJSONAPIResource instead of reflection whenever a resource implements it.
+type ResourceUnmarshaler interface {
+	UnmarshalJSONAPIResource(r *ResourceReader) error
+}
+
+// ResourceBuilder builds a resource object on behalf of a ResourceMarshaler.
+//
+// Its methods follow the same rules as the jsonapi struct tags of the same name.
+type ResourceBuilder struct {
+	v            any
+	ro           *resourceObject
+	d            *document
+	m            *Marshaler
+	foundPrimary bool
+}
+
+// Primary sets the type of the resource object and its id from the primary field value id.
+func (b *ResourceBuilder) Primary(resourceType string, id any) error {
+	b.ro.Type = resourceType
+	rid, err := schema.MarshalPrimary(b.v, id)
+	if err != nil {
+		return err
+	}
+	b.ro.ID = rid
+	b.foundPrimary = true
+
+	return nil
+}
+
+// Attribute adds the attribute named name with the given value, unless it is an absent Optional
+// or Nullable.
+func (b *ResourceBuilder) Attribute(name string, value any) {
+	if b.d.isRelationship {
+		// relationships must only be resource identifier objects so skip attributes
+		return
+	}
+	if isAbsent(value) {
+		return
+	}
+	b.ro.Attributes[name] = value
+}
+
+// AttributeOmitEmpty adds the attribute named name with the given value, unless the value is zero.
+func (b *ResourceBuilder) AttributeOmitEmpty(name string, value any) {
+	if isZero(value) {
+		return
+	}
+	b.Attribute(name, value)
+}
+
+// Relationship adds the relationship named name holding the related resource(s).
+func (b *ResourceBuilder) Relationship(name string, related any) error {
+	if b.d.isRelationship {
+		// relationship nesting must occur in include data, not the relationship fields
+		return nil
+	}
+	return b.d.marshalRelationship(b.v, b.ro, name, related, b.m)
+}
+
+// RelationshipOmitEmpty adds the relationship named name holding the related resource(s), unless it is zero.
+func (b *ResourceBuilder) RelationshipOmitEmpty(name string, related any) error {
+	if isZero(related) {
+		return nil
+	}
+	return b.Relationship(name, related)
+}
+
+// Meta sets the meta of the resource object.
+func (b *ResourceBuilder) Meta(meta any) error {
+	return b.d.marshalMeta(b.ro, meta, isZero(meta), b.m)
+}
+
+// ResourceReader reads a resource object on behalf of a ResourceUnmarshaler.
+//
+// Its methods follow the same rules as the jsonapi struct tags of the same name.
+type ResourceReader struct {
+	v          any
+	ro         *resourceObject
+	m          *Unmarshaler
+	setPrimary bool
+}
+
+// Primary checks the type of the resource object and unmarshals its id into the primary field pointed to by id.
+func (r *ResourceReader) Primary(resourceType string, omitEmpty bool, id any) error {
+	if r.setPrimary {
+		return ErrUnmarshalDuplicatePrimaryField
+	}
+	if err := r.ro.checkType(resourceType); err != nil {
+		return err
+	}
+
+	// if omitempty is allowed, skip if this is an empty id
+	if omitEmpty && r.ro.ID == "" {
+		return nil
+	}
+
+	if err := r.ro.unmarshalPrimary(r.v, reflect.ValueOf(id).Elem()); err != nil {
+		return err
+	}
+	r.setPrimary = true
+
+	return nil
+}
+
+// Relationship unmarshals the relationship named name, if present, into the field pointed to by related.
+func (r *ResourceReader) Relationship(name string, related any) error {
+	relDocument, ok := r.ro.Relationships[name]
+	if !ok {
+		return nil
+	}
+	return unmarshalRelationship(relDocument, reflect.ValueOf(related).Elem(), r.m)
+}
+
+// Presence sets the field pointed to by present to the set of the member names of the attributes
+// and relationships of the resource object.
+func (r *ResourceReader) Presence(present any) error {
+	return r.ro.unmarshalPresence(reflect.ValueOf(present).Elem())
+}
+
+// Meta unmarshals the meta of the resource object, if present, into the field pointed to by meta.
+func (r *ResourceReader) Meta(meta any) error {
+	if r.ro.Meta == nil {
+		return nil
+	}
+	return r.ro.unmarshalMeta(reflect.ValueOf(meta).Elem())
+}
+
+// Attributes unmarshals the attributes of the resource object into v.
+func (r *ResourceReader) Attributes(v any) error {
+	return r.ro.unmarshalAttributes(v, r.m)
+}
+
+func isZero(v any) bool {
+	return v == nil || reflect.ValueOf(v).IsZero()
+}
@@ -0,0 +1,89 @@
+// Code generated by jsonapi-gen. DO NOT EDIT.
+
+package jsonapi_test
+
+import "github.com/DataDog/jsonapi"
+
+// MarshalJSONAPIResource implements the jsonapi.ResourceMarshaler interface.
+func (v GeneratedArticle) MarshalJSONAPIResource(b *jsonapi.ResourceBuilder) error {
+	if err := b.Primary("articles", v.ID); err != nil {
+		return err
+	}
+	b.Attribute("title", v.Title)
+	if v.Tags != nil {
+		b.Attribute("tags", v.Tags)
+	}
+	if v.Author != nil {
+		if err := b.Relationship("author", v.Author); err != nil {
+			return err
+		}
+	}
+	if v.Comments != nil {
+		if err := b.Relationship("comments", v.Comments); err != nil {
+			return err
+		}
+	}
+	if err := b.Meta(v.Meta); err != nil {
+		return err
+	}
+	return nil
+}
+
+// UnmarshalJSONAPIResource implements the jsonapi.ResourceUnmarshaler interface.
+func (v *GeneratedArticle) UnmarshalJSONAPIResource(r *jsonapi.ResourceReader) error {
+	if err := r.Primary("articles", false, &v.ID); err != nil {
+		return err
+	}
+	if err := r.Relationship("author", &v.Author); err != nil {
+		return err
+	}
+	if err := r.Relationship("comments", &v.Comments); err != nil {
+		return err
+	}
+	if err := r.Meta(&v.Meta); err != nil {
+		return err
+	}
+	return r.Attributes(v)
+}
+
+// MarshalJSONAPIResource implements the jsonapi.ResourceMarshaler interface.
+func (v GeneratedAuthor) MarshalJSONAPIResource(b *jsonapi.ResourceBuilder) error {
+	if err := b.Primary("people", v.ID); err != nil {
+		return err
+	}
+	b.Attribute("name", v.Name)
+	return nil
+}
+
+// UnmarshalJSONAPIResource implements the jsonapi.ResourceUnmarshaler interface.
+func (v *GeneratedAuthor) UnmarshalJSONAPIResource(r *jsonapi.ResourceReader) error {
+	if err := r.Primary("people", false, &v.ID); err != nil {
+		return err
+	}
+	return r.Attributes(v)
+}
+
+// MarshalJSONAPIResource implements the jsonapi.ResourceMarshaler interface.
+func (v GeneratedComment) MarshalJSONAPIResource(b *jsonapi.ResourceBuilder) error {
+	if err := b.Primary("comments", v.ID); err != nil {
+		return err
+	}
+	b.Attribute("body", v.GeneratedCommentFields.Body)
+	if v.GeneratedCommentFields.Author != nil {
+		if err := b.Relationship("author", v.GeneratedCommentFields.Author); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSONAPIResource implements the jsonapi.ResourceUnmarshaler interface.
+func (v *GeneratedComment) UnmarshalJSONAPIResource(r *jsonapi.ResourceReader) error {
+	if err := r.Primary("comments", false, &v.ID); err != nil {
+		return err
+	}
+	if err := r.Relationship("author", &v.GeneratedCommentFields.Author); err != nil {
+		return err
+	}
+	return r.Attributes(v)
+}
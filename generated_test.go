@@ -0,0 +1,178 @@
+package jsonapi_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/jsonapi"
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+//go:generate go run ./cmd/jsonapi-gen -type GeneratedArticle,GeneratedAuthor,GeneratedComment generated_test.go
+
+type GeneratedArticle struct {
+	ID       string              `jsonapi:"primary,articles"`
+	Title    string              `jsonapi:"attribute" json:"title"`
+	Tags     []string            `jsonapi:"attribute" json:"tags,omitempty"`
+	Author   *GeneratedAuthor    `jsonapi:"relationship" json:"author,omitempty"`
+	Comments []*GeneratedComment `jsonapi:"relationship" json:"comments,omitempty"`
+	Meta     map[string]any      `jsonapi:"meta"`
+}
+
+func (a *GeneratedArticle) Link() *jsonapi.Link {
+	return &jsonapi.Link{Self: fmt.Sprintf("/articles/%s", a.ID)}
+}
+
+type GeneratedAuthor struct {
+	ID   string `jsonapi:"primary,people"`
+	Name string `jsonapi:"attribute" json:"name"`
+}
+
+type GeneratedCommentFields struct {
+	Body   string           `jsonapi:"attribute" json:"body"`
+	Author *GeneratedAuthor `jsonapi:"relationship" json:"author,omitempty"`
+}
+
+type GeneratedComment struct {
+	ID int `jsonapi:"primary,comments"`
+	GeneratedCommentFields
+}
+
+func (c GeneratedComment) MarshalID() string {
+	return fmt.Sprint(c.ID)
+}
+
+func (c *GeneratedComment) UnmarshalID(id string) error {
+	_, err := fmt.Sscan(id, &c.ID)
+	return err
+}
+
+// reflectedArticle has the same fields as GeneratedArticle without its generated methods.
+type reflectedArticle GeneratedArticle
+
+func (a *reflectedArticle) Link() *jsonapi.Link {
+	return (*GeneratedArticle)(a).Link()
+}
+
+var generatedArticle = GeneratedArticle{
+	ID:     "1",
+	Title:  "A",
+	Author: &GeneratedAuthor{ID: "1", Name: "A"},
+	Comments: []*GeneratedComment{
+		{ID: 1, GeneratedCommentFields: GeneratedCommentFields{Body: "A"}},
+		{ID: 2, GeneratedCommentFields: GeneratedCommentFields{Body: "B", Author: &GeneratedAuthor{ID: "1"}}},
+	},
+	Meta: map[string]any{"count": 2.0},
+}
+
+func TestGenerated(t *testing.T) {
+	t.Parallel()
+
+	var (
+		_ jsonapi.ResourceMarshaler   = GeneratedArticle{}
+		_ jsonapi.ResourceUnmarshaler = &GeneratedArticle{}
+	)
+
+	reflected := reflectedArticle(generatedArticle)
+	expect, err := jsonapi.Marshal(&reflected, jsonapi.MarshalInclude(generatedArticle.Author))
+	is.MustNoError(t, err)
+
+	actual, err := jsonapi.Marshal(&generatedArticle, jsonapi.MarshalInclude(generatedArticle.Author))
+	is.MustNoError(t, err)
+	is.EqualJSON(t, string(expect), string(actual))
+
+	var a GeneratedArticle
+	is.MustNoError(t, jsonapi.Unmarshal(actual, &a))
+	is.Equal(t, "1", a.ID)
+	is.Equal(t, generatedArticle.Meta, a.Meta)
+	is.Equal(t, 2, a.Comments[1].ID)
+
+	var r reflectedArticle
+	is.MustNoError(t, jsonapi.Unmarshal(actual, &r))
+
+	// both unmarshal the same resource object, which in turn marshals back to the same document
+	expect, err = jsonapi.Marshal(&r)
+	is.MustNoError(t, err)
+	actual, err = jsonapi.Marshal(&a)
+	is.MustNoError(t, err)
+	is.EqualJSON(t, string(expect), string(actual))
+
+	var c GeneratedComment
+	is.EqualError(t, &jsonapi.TypeError{Actual: "articles", Expected: []string{"comments"}}, jsonapi.Unmarshal(actual, &c))
+}
+
+// countingResource counts how often its resource object is built and read.
+type countingResource struct {
+	ID string
+
+	marshaled, unmarshaled int
+}
+
+func (c *countingResource) MarshalJSONAPIResource(b *jsonapi.ResourceBuilder) error {
+	c.marshaled++
+	return b.Primary("counters", c.ID)
+}
+
+func (c *countingResource) UnmarshalJSONAPIResource(r *jsonapi.ResourceReader) error {
+	c.unmarshaled++
+	return r.Primary("counters", false, &c.ID)
+}
+
+func TestResourceMarshalerIsUsed(t *testing.T) {
+	t.Parallel()
+
+	c := countingResource{ID: "1"}
+	b, err := jsonapi.Marshal(&c)
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":{"type":"counters","id":"1"}}`, string(b))
+	is.Equal(t, 1, c.marshaled)
+
+	var u countingResource
+	is.MustNoError(t, jsonapi.Unmarshal(b, &u))
+	is.Equal(t, "1", u.ID)
+	is.Equal(t, 1, u.unmarshaled)
+}
+
+func BenchmarkGenerated(b *testing.B) {
+	articles := make([]*GeneratedArticle, 1000)
+	reflected := make([]*reflectedArticle, len(articles))
+	for i := range articles {
+		a := generatedArticle
+		a.ID = fmt.Sprint(i + 1)
+		articles[i] = &a
+		reflected[i] = (*reflectedArticle)(&a)
+	}
+
+	body, err := jsonapi.Marshal(articles)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarks := []struct {
+		name string
+		v    any
+		into func() any
+	}{
+		{name: "Generated", v: articles, into: func() any { return new([]GeneratedArticle) }},
+		{name: "Reflected", v: reflected, into: func() any { return new([]reflectedArticle) }},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run("Marshal"+bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := jsonapi.Marshal(bm.v); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run("Unmarshal"+bm.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := jsonapi.Unmarshal(body, bm.into()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package jsonapi
+
+import "reflect"
+
+// UnmarshalPreserveIdentity unmarshals each resource of a document, identified by its type and id,
+// into a single Go value. Relationship fields and slice elements which are pointers (or interfaces
+// holding pointers, see RegisterType) share the pointer of the primary data or of the first value
+// the resource was unmarshaled into, so that cyclic relationships among primary and included
+// resources (e.g. article → author → articles) come out as an object graph rather than as copies.
+//
+// Struct values, like the elements of a []Article, can't be shared and still hold copies. Resources
+// without an id, like the ones sent by clients to create them, are never shared.
+func UnmarshalPreserveIdentity() UnmarshalOption {
+	return func(m *Unmarshaler) {
+		m.preserveIdentity = true
+	}
+}
+
+// identities tracks the Go values the resources of a single document are unmarshaled into.
+type identities struct {
+	// values maps resource identifiers to pointers to the structs they are unmarshaled into
+	values map[string]*identity
+	// primary holds the resource objects of the primary data
+	primary map[*resourceObject]bool
+	// included holds the identifiers of the included resources
+	included map[string]bool
+}
+
+// identity is a Go value a resource is unmarshaled into.
+type identity struct {
+	v reflect.Value
+	// complete is true once the full resource object, not just its identifier, was unmarshaled
+	complete bool
+}
+
+// newIdentities returns the identities of the resources of d, which must be fully linked with
+// aliased relationships (see verifyFullLinkage).
+func newIdentities(d *document) *identities {
+	ids := &identities{
+		values:   make(map[string]*identity),
+		primary:  make(map[*resourceObject]bool),
+		included: make(map[string]bool),
+	}
+	for _, ro := range d.getResourceObjectSlice() {
+		ids.primary[ro] = true
+	}
+	for _, ro := range d.Included {
+		ids.included[ro.getIdentifier()] = true
+	}
+	return ids
+}
+
+// lookup returns the pointer the resource object was already unmarshaled into, if it is assignable
+// to t. It always returns false when identities aren't preserved.
+func (ids *identities) lookup(ro *resourceObject, t reflect.Type) (reflect.Value, bool) {
+	if ids == nil || ro.ID == "" {
+		return reflect.Value{}, false
+	}
+	id, ok := ids.values[ro.getIdentifier()]
+	if !ok || !id.v.Type().AssignableTo(t) {
+		return reflect.Value{}, false
+	}
+	return id.v, true
+}
+
+// claim records that the resource object is being unmarshaled into pv, a pointer to a struct, and
+// returns whether unmarshaling can be skipped because pv already holds the resource. This is what
+// breaks the cycles of aliased relationships.
+func (ids *identities) claim(ro *resourceObject, pv reflect.Value) bool {
+	if ids == nil || ro.ID == "" {
+		return false
+	}
+
+	// primary data and included resources are complete, while relationships to primary data
+	// only hold resource identifiers
+	rid := ro.getIdentifier()
+	complete := ids.primary[ro] || ids.included[rid]
+
+	id, ok := ids.values[rid]
+	switch {
+	case !ok:
+		ids.values[rid] = &identity{v: pv, complete: complete}
+		return false
+	case id.v.Type() != pv.Type() || id.v.Pointer() != pv.Pointer():
+		// another value of the same resource, e.g. a slice element or a struct of another type
+		return false
+	case id.complete || !complete:
+		return true
+	default:
+		id.complete = true
+		return false
+	}
+}
@@ -0,0 +1,123 @@
+package jsonapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestUnmarshalPreserveIdentity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		// do unmarshals the body and returns pairs of values which must be the same pointer
+		do func(body []byte) ([][2]any, error)
+	}{
+		{
+			description: "resources linked many times",
+			given:       articlesRelatedComplexBody,
+			do: func(body []byte) ([][2]any, error) {
+				var a []*ArticleRelated
+				err := Unmarshal(body, &a, UnmarshalPreserveIdentity())
+				if err != nil {
+					return nil, err
+				}
+				return [][2]any{
+					{a[0].Author, a[3].Author},
+					{a[0].Author, a[0].Comments[2].Author},
+					{a[1].Author, a[0].Comments[0].Author},
+				}, nil
+			},
+		}, {
+			description: "cycle through primary data",
+			given:       bookWithWriterBody,
+			do: func(body []byte) ([][2]any, error) {
+				var b Book
+				err := Unmarshal(body, &b, UnmarshalPreserveIdentity())
+				if err != nil {
+					return nil, err
+				}
+				return [][2]any{
+					{&b, b.Writer.Books[0]},
+					{b.Writer, b.Writer.Books[1].Writer},
+				}, nil
+			},
+		}, {
+			description: "primary data linked before it is unmarshaled",
+			given:       booksWithWriterBody,
+			do: func(body []byte) ([][2]any, error) {
+				var b []*Book
+				err := Unmarshal(body, &b, UnmarshalPreserveIdentity())
+				if err != nil {
+					return nil, err
+				}
+				if b[1].Title != "B" {
+					return nil, fmt.Errorf("unexpected title %q", b[1].Title)
+				}
+				return [][2]any{
+					{b[0], b[0].Writer.Books[0]},
+					{b[1], b[0].Writer.Books[1]},
+					{b[0].Writer, b[1].Writer},
+				}, nil
+			},
+		}, {
+			description: "polymorphic relationships",
+			given:       projectOwnedByTeamBody,
+			do: func(body []byte) ([][2]any, error) {
+				var p Project
+				err := Unmarshal(body, &p, UnmarshalPreserveIdentity(), UnmarshalRegisterType("teams", Team{}))
+				if err != nil {
+					return nil, err
+				}
+				return [][2]any{{p.Owner, p.Watchers[1]}}, nil
+			},
+		}, {
+			description: "included resources",
+			given:       bookWithWriterBody,
+			do: func(body []byte) ([][2]any, error) {
+				var b Book
+				var included []any
+				err := Unmarshal(body, &b, UnmarshalPreserveIdentity(), UnmarshalIncluded(&included),
+					UnmarshalRegisterType("writers", &Writer{}), UnmarshalRegisterType("books", &Book{}))
+				if err != nil {
+					return nil, err
+				}
+				return [][2]any{{b.Writer, included[0]}, {b.Writer.Books[1], included[1]}}, nil
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			pairs, err := tc.do([]byte(tc.given))
+			is.MustNoError(t, err)
+			for _, pair := range pairs {
+				is.Equal(t, pair[0], pair[1])
+				is.Equal(t, true, pair[0] == pair[1])
+			}
+		})
+	}
+}
+
+func TestUnmarshalPreserveIdentityCopies(t *testing.T) {
+	t.Parallel()
+
+	// identities are per document, and only preserved when asked
+	var a, b []*ArticleRelated
+	m := NewUnmarshaler(UnmarshalPreserveIdentity())
+	is.MustNoError(t, m.Unmarshal([]byte(articlesRelatedComplexBody), &a))
+	is.MustNoError(t, m.Unmarshal([]byte(articlesRelatedComplexBody), &b))
+	is.Equal(t, false, a[0].Author == b[0].Author)
+
+	var c []*ArticleRelated
+	is.MustNoError(t, Unmarshal([]byte(articlesRelatedComplexBody), &c))
+	is.Equal(t, c[0].Author, c[3].Author)
+	is.Equal(t, false, c[0].Author == c[3].Author)
+}
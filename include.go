@@ -0,0 +1,193 @@
+package jsonapi
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// MarshalIncludePaths builds Document.Included from the given relationship paths, creating a
+// compound document as defined by https://jsonapi.org/format/1.0/#fetching-includes. The input is
+// the value of the `include` query parameter, a comma-separated list of dot-separated relationship
+// names (e.g. "author,comments.author").
+//
+// The resources reachable from the primary data through each path, including the intermediate
+// ones, are included once per type and id, in addition to any given to MarshalInclude. A path
+// which isn't made of relationship fields is an error whose Status is 400 (Bad Request), as
+// required by the specification, and whose Source.Parameter is "include".
+func MarshalIncludePaths(include string) MarshalOption {
+	paths := splitIncludePaths(include)
+
+	return func(m *Marshaler) {
+		m.includePaths = paths
+	}
+}
+
+// splitIncludePaths splits the value of an include query parameter into relationship paths,
+// skipping empty ones.
+func splitIncludePaths(include string) [][]string {
+	var paths [][]string
+	for _, path := range strings.Split(include, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, strings.Split(path, "."))
+		}
+	}
+	return paths
+}
+
+// includeError returns the error of the include path whose last relationship name isn't a
+// relationship of the given resource type.
+func includeError(path []string, resourceType string) *Error {
+	return &Error{
+		Status: Status(400),
+		Title:  "Invalid include path",
+		Detail: fmt.Sprintf("%q is not a relationship of %q in include path %q", path[len(path)-1], resourceType, strings.Join(path, ".")),
+		Source: &ErrorSource{Parameter: "include"},
+	}
+}
+
+// includePathResources returns the resource objects reachable from v through the include paths of
+// m, skipping the ones whose identifiers are in seen, which is updated with the returned ones.
+func (d *document) includePathResources(v any, m *Marshaler, seen map[string]bool) ([]*resourceObject, error) {
+	var included []*resourceObject
+
+	for _, path := range m.includePaths {
+		// paths are checked against the types first, since there may be no resources to walk
+		if vt := reflect.TypeOf(v); vt != nil {
+			if err := checkIncludePath(vt, path); err != nil {
+				return nil, err
+			}
+		}
+
+		resources := relatedResources(reflect.ValueOf(v))
+		for i, name := range path {
+			var next []reflect.Value
+			for _, rv := range resources {
+				related, resourceType, ok := relationshipResources(rv, name)
+				if !ok {
+					return nil, includeError(path[:i+1], resourceType)
+				}
+				next = append(next, related...)
+			}
+
+			for _, rv := range next {
+				iv := rv.Interface()
+				ro, err := d.makeResourceObject(iv, reflect.TypeOf(iv), m)
+				if err != nil {
+					return nil, err
+				}
+				if rid := ro.getIdentifier(); !seen[rid] {
+					seen[rid] = true
+					included = append(included, ro)
+				}
+			}
+			resources = next
+		}
+	}
+
+	return included, nil
+}
+
+// checkIncludePath returns an error if path isn't made of relationships of the resources of type
+// t, as far as the struct types of relationship fields tell. Relationship fields typed as an
+// interface are checked against their values instead.
+func checkIncludePath(t reflect.Type, path []string) error {
+	for i, name := range path {
+		for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t == reflect.TypeOf(Resource{}) {
+			return nil
+		}
+
+		// tag errors were already returned when marshaling the resources
+		fields, _ := schema.CachedTypeFields(t)
+
+		var resourceType string
+		var related *schema.Field
+		for j := range fields {
+			ft := &fields[j]
+			switch {
+			case ft.Tag.Directive == schema.Primary:
+				resourceType = ft.Tag.ResourceType
+			case ft.Tag.Directive == schema.Relationship && ft.Exported && ft.MemberName == name:
+				related = ft
+			}
+		}
+		if related == nil {
+			return includeError(path[:i+1], resourceType)
+		}
+		t = related.Type
+	}
+	return nil
+}
+
+// relationshipResources returns the resources of the relationship with the given member name of
+// the resource rv, along with the resource type of rv and whether it has such a relationship.
+func relationshipResources(rv reflect.Value, name string) ([]reflect.Value, string, bool) {
+	if r, ok := rv.Interface().(*Resource); ok {
+		// the relationships of dynamic resources only hold resource linkage
+		_, ok := r.Relationship(name)
+		return nil, r.Type(), ok
+	}
+
+	sv := derefValue(rv)
+
+	// tag errors were already returned when marshaling the resource
+	fields, _ := schema.CachedTypeFields(sv.Type())
+
+	var resourceType string
+	for i := range fields {
+		ft := &fields[i]
+		switch {
+		case ft.Tag.Directive == schema.Primary:
+			resourceType = ft.Tag.ResourceType
+		case ft.Tag.Directive == schema.Relationship && ft.Exported && ft.MemberName == name:
+			fv, ok := schema.FieldByIndex(sv, ft.Index, false)
+			if !ok {
+				return nil, "", true
+			}
+			return relatedResources(fv), "", true
+		}
+	}
+
+	return nil, resourceType, false
+}
+
+// relatedResources returns the non-empty resources held by rv, which is a resource, a slice of
+// resources, or a pointer or interface holding one of these.
+func relatedResources(rv reflect.Value) []reflect.Value {
+	switch rv.Kind() {
+	case reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+		return relatedResources(rv.Elem())
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		if rv.Elem().Kind() == reflect.Struct {
+			return []reflect.Value{rv}
+		}
+		return relatedResources(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		var resources []reflect.Value
+		for i := 0; i < rv.Len(); i++ {
+			resources = append(resources, relatedResources(rv.Index(i))...)
+		}
+		return resources
+	case reflect.Struct:
+		if rv.IsZero() {
+			return nil
+		}
+		// keep methods with pointer receivers, like LinkRelation
+		if rv.CanAddr() {
+			return []reflect.Value{rv.Addr()}
+		}
+		return []reflect.Value{rv}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package jsonapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestMarshalIncludePaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       any
+		opts        []MarshalOption
+		expect      string
+		expectError *Error
+	}{
+		{
+			description: "nested paths",
+			given:       articlesRelatedComplex,
+			opts: []MarshalOption{
+				MarshalJSONAPI(map[string]any{"meta_kind": "jsonapi meta"}),
+				MarshalMeta(map[string]any{"meta_kind": "document-level meta"}),
+				MarshalIncludePaths("author,comments.author"),
+			},
+			expect: articlesRelatedComplexBody,
+		}, {
+			description: "intermediate resources are included",
+			given:       &articleRelatedCommentsNested,
+			opts:        []MarshalOption{MarshalIncludePaths("comments.author")},
+			expect:      articleRelatedCommentsNestedWithIncludeBody,
+		}, {
+			description: "duplicates and primary data are skipped",
+			given:       articlesRelatedComplex[:1],
+			opts:        []MarshalOption{MarshalIncludePaths("comments.author, author")},
+			expect:      `{"data":[{"id":"1","type":"articles","attributes":{"title":"Bazel 101"},"relationships":{"author":{"data":{"id":"1","type":"author"},"links":{"self":"http://example.com/articles/1/relationships/author","related":"http://example.com/articles/1/author"}},"comments":{"data":[{"id":"11","type":"comments"},{"id":"12","type":"comments"},{"id":"13","type":"comments"}],"links":{"self":"http://example.com/articles/1/relationships/comments","related":"http://example.com/articles/1/comments"}}}}],"included":[{"id":"11","type":"comments","attributes":{"archived":true,"body":"Why is Bazel so slow on my computerr?"},"relationships":{"author":{"data":{"id":"2","type":"author"},"meta":{"count":10},"links":{"self":"http://example.com/comments/11/relationships/author","related":"http://example.com/comments/11/author"}}}},{"id":"12","type":"comments","attributes":{"body":"Why is Bazel so slow on my computer?"},"relationships":{"author":{"data":{"id":"2","type":"author"},"meta":{"count":10},"links":{"self":"http://example.com/comments/12/relationships/author","related":"http://example.com/comments/12/author"}}}},{"id":"13","type":"comments","attributes":{"body":"Just use an Apple M1"},"relationships":{"author":{"data":{"id":"1","type":"author"},"links":{"self":"http://example.com/comments/13/relationships/author","related":"http://example.com/comments/13/author"}}}},{"id":"2","type":"author","attributes":{"name":"B"},"meta":{"count":10}},{"id":"1","type":"author","attributes":{"name":"A"}}]}`,
+		}, {
+			description: "with MarshalInclude",
+			given:       &articleRelatedCommentsNested,
+			opts:        []MarshalOption{MarshalInclude(&commentAWithAuthor), MarshalIncludePaths("comments.author")},
+			expect:      `{"data":{"id":"1","type":"articles","attributes":{"title":"A"},"relationships":{"comments":{"data":[{"id":"1","type":"comments"}],"links":{"self":"http://example.com/articles/1/relationships/comments","related":"http://example.com/articles/1/comments"}}}},"included":[{"id":"1","type":"comments","attributes":{"body":"A"},"relationships":{"author":{"data":{"id":"1","type":"author"},"links":{"self":"http://example.com/comments/1/relationships/author","related":"http://example.com/comments/1/author"}}}},{"id":"1","type":"author","attributes":{"name":"A"}}]}`,
+		}, {
+			description: "polymorphic relationships",
+			given:       &Project{ID: "1", Owner: &Team{ID: "1", Name: "T", Members: []Principal{User{ID: "2", Name: "B"}}}},
+			opts:        []MarshalOption{MarshalIncludePaths("owner.members")},
+			expect:      `{"data":{"id":"1","type":"projects","relationships":{"owner":{"data":{"id":"1","type":"teams"}}}},"included":[{"id":"1","type":"teams","attributes":{"name":"T"},"relationships":{"members":{"data":[{"id":"2","type":"users"}]}}},{"id":"2","type":"users","attributes":{"name":"B"}}]}`,
+		}, {
+			description: "empty relationship",
+			given:       &articleRelated,
+			opts:        []MarshalOption{MarshalIncludePaths("author,comments.author")},
+			expect:      `{"data":{"id":"1","type":"articles","attributes":{"title":"A"}}}`,
+		}, {
+			description: "unknown path",
+			given:       &articleRelated,
+			opts:        []MarshalOption{MarshalIncludePaths("comments.article")},
+			expectError: &Error{
+				Status: Status(400),
+				Title:  "Invalid include path",
+				Detail: `"article" is not a relationship of "comments" in include path "comments.article"`,
+				Source: &ErrorSource{Parameter: "include"},
+			},
+		}, {
+			description: "unknown path without primary data",
+			given:       []*ArticleRelated{},
+			opts:        []MarshalOption{MarshalIncludePaths("title")},
+			expectError: &Error{
+				Status: Status(400),
+				Title:  "Invalid include path",
+				Detail: `"title" is not a relationship of "articles" in include path "title"`,
+				Source: &ErrorSource{Parameter: "include"},
+			},
+		}, {
+			description: "unknown path of a polymorphic relationship",
+			given:       &Project{ID: "1", Owner: User{ID: "1"}},
+			opts:        []MarshalOption{MarshalIncludePaths("owner.members")},
+			expectError: &Error{
+				Status: Status(400),
+				Title:  "Invalid include path",
+				Detail: `"members" is not a relationship of "users" in include path "owner.members"`,
+				Source: &ErrorSource{Parameter: "include"},
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := Marshal(tc.given, tc.opts...)
+			if tc.expectError != nil {
+				is.Equal(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.EqualJSON(t, tc.expect, string(actual))
+		})
+	}
+}
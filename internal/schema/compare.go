@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// IsComparable returns true if values of type t can be compared by Compare.
+func IsComparable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return t == reflect.TypeOf(time.Time{})
+	}
+}
+
+// Compare returns -1, 0 or 1 depending on whether a is less than, equal to or greater than b,
+// which are values of the same comparable type, or pointers to them. Invalid values and nil
+// pointers are the smallest.
+func Compare(a, b reflect.Value) int {
+	for a.IsValid() && a.Kind() == reflect.Pointer {
+		if a.IsNil() {
+			a = reflect.Value{}
+			break
+		}
+		a = a.Elem()
+	}
+	for b.IsValid() && b.Kind() == reflect.Pointer {
+		if b.IsNil() {
+			b = reflect.Value{}
+			break
+		}
+		b = b.Elem()
+	}
+
+	switch {
+	case !a.IsValid() && !b.IsValid():
+		return 0
+	case !a.IsValid():
+		return -1
+	case !b.IsValid():
+		return 1
+	}
+
+	switch a.Kind() {
+	case reflect.Bool:
+		return compareOrdered(boolInt(a.Bool()), boolInt(b.Bool()))
+	case reflect.String:
+		return strings.Compare(a.String(), b.String())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int(), b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return compareOrdered(a.Uint(), b.Uint())
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float(), b.Float())
+	default:
+		at, bt := a.Interface().(time.Time), b.Interface().(time.Time)
+		switch {
+		case at.Before(bt):
+			return -1
+		case at.After(bt):
+			return 1
+		default:
+			return 0
+		}
+	}
+}
+
+func compareOrdered[T int64 | uint64 | float64](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,71 @@
+package schema
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrInvalidPrimaryField indicates that the id (primary) fields was invalid.
+var ErrInvalidPrimaryField = errors.New("primary/id field must be a string or implement fmt.Stringer or in a struct which implements MarshalIdentifier")
+
+// DerefType returns the type pointed to by t through any number of pointers.
+func DerefType(t reflect.Type) reflect.Type {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return DerefType(t.Elem())
+	}
+	return t
+}
+
+// FieldByIndex returns the nested field of the struct v corresponding to index. If the field is
+// reached through a nil embedded pointer, it is allocated when alloc is true, otherwise FieldByIndex
+// returns false.
+func FieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// MarshalPrimary returns the id of the resource v, whose primary field holds fv.
+func MarshalPrimary(v any, fv any) (string, error) {
+	// to marshal the id we follow these rules
+	//     1. Use MarshalIdentifier if it is implemented
+	//     2. Use the value directly if it is a string
+	//     3. Use fmt.Stringer if it is implemented
+	//     4. Use encoding.TextMarshaler if it is implemented
+	//     5. Fail
+
+	if vm, ok := v.(interface{ MarshalID() string }); ok {
+		return vm.MarshalID(), nil
+	}
+
+	if vs, ok := fv.(string); ok {
+		return vs, nil
+	}
+
+	if _, ok := fv.(fmt.Stringer); ok {
+		return fmt.Sprintf("%s", fv), nil
+	}
+
+	if fvm, ok := fv.(encoding.TextMarshaler); ok {
+		vb, err := fvm.MarshalText()
+		if err != nil {
+			return "", err
+		}
+		return string(vb), nil
+	}
+
+	return "", ErrInvalidPrimaryField
+}
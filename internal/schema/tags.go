@@ -0,0 +1,213 @@
+// Package schema reads the jsonapi struct tags of resource types, and compares their field values.
+// It is shared by jsonapi and its subpackages, so that they agree on the fields of a type.
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Directive is the first value of a jsonapi struct tag.
+type Directive int
+
+const (
+	Primary Directive = iota
+	Attribute
+	Meta
+	Relationship
+	Presence
+	Invalid
+)
+
+func parseDirective(v string) (Directive, bool) {
+	switch v {
+	case "primary":
+		return Primary, true
+	case "attribute", "attr":
+		return Attribute, true
+	case "meta":
+		return Meta, true
+	case "relationship", "rel":
+		return Relationship, true
+	case "presence":
+		return Presence, true
+	}
+	return Invalid, false
+}
+
+// Tag is a parsed jsonapi struct tag.
+type Tag struct {
+	Directive    Directive
+	ResourceType string // only valid for primary
+	OmitEmpty    bool
+}
+
+// TagError indicates that an invalid struct tag was encountered.
+type TagError struct {
+	TagName string
+	Field   string
+	Reason  string
+}
+
+// Error implements the error interface.
+func (e *TagError) Error() string {
+	return fmt.Sprintf("invalid %q tag on field %q: %s", e.TagName, e.Field, e.Reason)
+}
+
+// ParseJSONTag returns the member name of the struct field f given by its json tag, whether f is
+// exported, and whether its json tag has the omitempty option.
+func ParseJSONTag(f reflect.StructField) (string, bool, bool) {
+	t := f.Tag.Get("json")
+	if t == "" {
+		if f.IsExported() {
+			return f.Name, true, false
+		}
+		return t, false, false
+	}
+	ts := strings.Split(t, ",")
+
+	var omit bool
+	if len(ts) > 1 {
+		omit = ts[1] == "omitempty"
+	}
+
+	return ts[0], f.IsExported(), omit
+}
+
+// ParseJSONAPITag returns the jsonapi tag of the struct field f, or nil if it has none.
+func ParseJSONAPITag(f reflect.StructField) (*Tag, error) {
+	t := f.Tag.Get("jsonapi")
+	ts := strings.Split(t, ",")
+
+	var omitEmpty bool
+	switch len(ts) {
+	case 3:
+		if ts[2] == "omitempty" {
+			omitEmpty = true
+			break // good
+		}
+	case 2:
+		break // good
+	case 1:
+		// this is a missing tag
+		if ts[0] == "" {
+			return nil, nil
+		}
+	default:
+		return nil, &TagError{
+			TagName: "jsonapi",
+			Field:   f.Name,
+			Reason:  "expected format {directive},{optional:type},{optional:omitempty}",
+		}
+	}
+
+	d, ok := parseDirective(ts[0])
+	if !ok {
+		return nil, &TagError{TagName: "jsonapi", Field: f.Name, Reason: "invalid directive"}
+	}
+
+	tag := &Tag{Directive: d, OmitEmpty: omitEmpty}
+	if d == Primary {
+		if len(ts) < 2 {
+			return nil, &TagError{
+				TagName: "jsonapi",
+				Field:   f.Name,
+				Reason:  "missing type in primary directive",
+			}
+		}
+		tag.ResourceType = ts[1]
+	}
+
+	return tag, nil
+}
+
+// Field holds the parsed struct tags of a jsonapi tagged struct field, along with the index
+// sequence used to reach it from the outermost struct through embedded structs.
+type Field struct {
+	Index []int
+	Name  string // the field name, used for error reporting
+	Type  reflect.Type
+	Tag   *Tag
+
+	// MemberName, Exported and OmitEmpty are the values returned by ParseJSONTag
+	MemberName string
+	Exported   bool
+	OmitEmpty  bool
+}
+
+// typeFields holds the compiled fields of a struct type, or the tag error encountered compiling them.
+type typeFields struct {
+	fields []Field
+	err    error
+}
+
+// fieldCache is a map[reflect.Type]*typeFields of the struct types seen so far.
+var fieldCache sync.Map
+
+// CachedTypeFields returns the jsonapi tagged fields of the given struct type, including the
+// fields of untagged embedded structs. The struct tags of any given type are only parsed once.
+// The error is a *TagError.
+func CachedTypeFields(t reflect.Type) ([]Field, error) {
+	if tf, ok := fieldCache.Load(t); ok {
+		return tf.(*typeFields).fields, tf.(*typeFields).err
+	}
+
+	fields, err := computeTypeFields(t, nil, make(map[reflect.Type]bool))
+	tf, _ := fieldCache.LoadOrStore(t, &typeFields{fields: fields, err: err})
+	return tf.(*typeFields).fields, tf.(*typeFields).err
+}
+
+// computeTypeFields returns the fields of the struct type t, reached by the given index sequence.
+// The types being visited are skipped when embedded again, like encoding/json does, so that
+// recursive embedding terminates.
+func computeTypeFields(t reflect.Type, index []int, visiting map[reflect.Type]bool) ([]Field, error) {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	fields := make([]Field, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		tag, err := ParseJSONAPITag(sf)
+		if err != nil {
+			return nil, err
+		}
+		if tag == nil {
+			// untagged embedded structs have their fields promoted, like encoding/json does
+			if et := sf.Type; sf.Anonymous {
+				if et.Kind() == reflect.Pointer {
+					et = et.Elem()
+				}
+				if et.Kind() == reflect.Struct && !visiting[et] {
+					embedded, err := computeTypeFields(et, fieldIndex, visiting)
+					if err != nil {
+						return nil, err
+					}
+					fields = append(fields, embedded...)
+				}
+			}
+			// this field is not tagged w/ jsonapi and will be ignored
+			continue
+		}
+
+		memberName, exported, omitEmpty := ParseJSONTag(sf)
+		fields = append(fields, Field{
+			Index:      fieldIndex,
+			Name:       sf.Name,
+			Type:       sf.Type,
+			Tag:        tag,
+			MemberName: memberName,
+			Exported:   exported,
+			OmitEmpty:  omitEmpty,
+		})
+	}
+
+	return fields, nil
+}
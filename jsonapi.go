@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+
+	"github.com/DataDog/jsonapi/internal/schema"
 )
 
 // ResourceObject is a JSON:API resource object as defined by https://jsonapi.org/format/1.0/#document-resource-objects
@@ -15,14 +17,25 @@ type resourceObject struct {
 	Relationships map[string]*document `json:"relationships,omitempty"`
 	Meta          any                  `json:"meta,omitempty"`
 	Links         *Link                `json:"links,omitempty"`
+
+	// structType is the struct type a resource object was made from when marshaling
+	structType reflect.Type
+
+	// rawAttributes holds the undecoded attributes of an unmarshaled resource object, so that they
+	// can be decoded directly into the destination struct
+	rawAttributes json.RawMessage
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
+//
+// Attributes and meta are kept undecoded, as rawAttributes and a json.RawMessage Meta respectively.
 func (ro *resourceObject) UnmarshalJSON(data []byte) error {
 	type alias resourceObject
 
 	auxRaw := &struct {
-		Rels map[string]json.RawMessage `json:"relationships,omitempty"`
+		Attrs json.RawMessage            `json:"attributes,omitempty"`
+		Meta  json.RawMessage            `json:"meta,omitempty"`
+		Rels  map[string]json.RawMessage `json:"relationships,omitempty"`
 		*alias
 	}{
 		alias: (*alias)(ro),
@@ -31,6 +44,11 @@ func (ro *resourceObject) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	ro.rawAttributes = nonNullRawMessage(auxRaw.Attrs)
+	if meta := nonNullRawMessage(auxRaw.Meta); meta != nil {
+		ro.Meta = meta
+	}
+
 	ro.Relationships = make(map[string]*document, len(auxRaw.Rels))
 	for name, raw := range auxRaw.Rels {
 		// mark the created sub-documents as relationships so that the document Unmarshaler
@@ -48,19 +66,36 @@ func (ro *resourceObject) getIdentifier() string {
 	return fmt.Sprintf("{Type: %v, ID: %v}", ro.Type, ro.ID)
 }
 
-// JSONAPI is a JSON:API object as defined by https://jsonapi.org/format/1.0/#document-jsonapi-object.
-type jsonAPI struct {
+// JSONAPIObject is a JSON:API object as defined by https://jsonapi.org/format/1.0/#document-jsonapi-object.
+type JSONAPIObject struct {
 	Version string `json:"version"`
 	Meta    any    `json:"meta,omitempty"`
 }
 
+// nonNullRawMessage returns nil if the given raw message is empty or the JSON null literal.
+func nonNullRawMessage(raw json.RawMessage) json.RawMessage {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return raw
+}
+
+// rawMeta returns the JSON encoding of the given meta value, which is returned as-is if it is
+// an undecoded json.RawMessage.
+func rawMeta(m any) ([]byte, error) {
+	if raw, ok := m.(json.RawMessage); ok {
+		return raw, nil
+	}
+	return json.Marshal(m)
+}
+
 // checkMeta returns a type error if the given meta value is not map-like
 func checkMeta(m any) *TypeError {
 	if m == nil {
 		return nil
 	}
 
-	mt := derefType(reflect.TypeOf(m))
+	mt := schema.DerefType(reflect.TypeOf(m))
 	if mt.Kind() == reflect.Struct || mt.Kind() == reflect.Map {
 		return nil
 	}
@@ -143,11 +178,15 @@ type document struct {
 	// isRelationship marks a document as a relationship sub-document (within primary data)
 	isRelationship bool `json:"-"`
 
+	// omitData marks a relationship document which was unmarshaled without a "data" member, so that
+	// it is marshaled back without one
+	omitData bool `json:"-"`
+
 	// Meta is Meta Information as defined by https://jsonapi.org/format/1.0/#document-meta.
 	Meta any `json:"meta,omitempty"`
 
 	// JSONAPI is a JSON:API object as defined by https://jsonapi.org/format/1.0/#document-jsonapi-object.
-	JSONAPI *jsonAPI `json:"jsonapi,omitempty"`
+	JSONAPI *JSONAPIObject `json:"jsonapi,omitempty"`
 
 	// Errors is a list of JSON:API error objects as defined by https://jsonapi.org/format/1.1/#error-objects.
 	Errors []*Error `json:"errors,omitempty"`
@@ -166,10 +205,16 @@ func newDocument() *document {
 	}
 }
 
+// isErrorDocument returns true if the parsed document d has an errors member, even an empty one,
+// which is then reported as an error.
+func (d *document) isErrorDocument() bool {
+	return d.Errors != nil
+}
+
 // MarshalJSON implements the json.Marshaler interface.
 func (d *document) MarshalJSON() ([]byte, error) {
-	// if we get errors, force exclusion of the Data field
-	if len(d.Errors) > 0 {
+	// if we get errors, or a relationship without data, force exclusion of the Data field
+	if len(d.Errors) > 0 || d.omitData {
 		type alias document
 		return json.Marshal(&struct{ *alias }{alias: (*alias)(d)})
 	}
@@ -202,6 +247,7 @@ func (d *document) UnmarshalJSON(data []byte) error {
 
 	auxRaw := &struct {
 		Data json.RawMessage `json:"data,omitempty"`
+		Meta json.RawMessage `json:"meta,omitempty"`
 		*alias
 	}{
 		alias: (*alias)(d),
@@ -210,6 +256,11 @@ func (d *document) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
+	// keep meta undecoded, it is only decoded when requested via UnmarshalMeta
+	if meta := nonNullRawMessage(auxRaw.Meta); meta != nil {
+		d.Meta = meta
+	}
+
 	switch string(auxRaw.Data) {
 	case "":
 		// no "data" field -> check that other required members are present
@@ -217,6 +268,7 @@ func (d *document) UnmarshalJSON(data []byte) error {
 			if d.Meta == nil && d.Links == nil {
 				return ErrRelationshipMissingRequiredMembers
 			}
+			d.omitData = true
 		} else if d.Meta == nil && d.Errors == nil {
 			return ErrDocumentMissingRequiredMembers
 		}
@@ -68,6 +68,9 @@ var (
 	articleEmbedded                              = ArticleEmbedded{ID: "1", Title: "A", Metadata: Metadata{LastModified: time.Date(1989, 06, 15, 0, 0, 0, 0, time.UTC)}}
 	articleEmbeddedPointer                       = ArticleEmbeddedPointer{ID: "1", Title: "A", Metadata: &Metadata{LastModified: time.Date(1989, 06, 15, 0, 0, 0, 0, time.UTC)}}
 
+	// articlesLarge is a large list of articles with relationships, used by benchmarks
+	articlesLarge = makeArticlesLarge(1000)
+
 	// articles with optional meta
 	articleAWithMeta              = ArticleWithMeta{ID: "1", Title: "A", Meta: &ArticleMetrics{Views: 10, Reads: 4}}
 	articleWithResourceObjectMeta = ArticleWithResourceObjectMeta{
@@ -117,19 +120,20 @@ var (
 			Author: &authorA,
 		},
 	}
+	articlesRelatedComplexIncluded = []any{
+		authorA,
+		authorBWithMeta,
+		articlesRelatedComplex[0].Comments[0],
+		articlesRelatedComplex[0].Comments[1],
+		articlesRelatedComplex[0].Comments[2],
+		articlesRelatedComplex[1].Comments[0],
+		articlesRelatedComplex[2].Comments[0],
+		articlesRelatedComplex[2].Comments[1],
+	}
 	articlesRelatedComplexMarshalOptions = []MarshalOption{
 		MarshalJSONAPI(map[string]any{"meta_kind": "jsonapi meta"}),
 		MarshalMeta(map[string]any{"meta_kind": "document-level meta"}),
-		MarshalInclude(
-			authorA,
-			authorBWithMeta,
-			articlesRelatedComplex[0].Comments[0],
-			articlesRelatedComplex[0].Comments[1],
-			articlesRelatedComplex[0].Comments[2],
-			articlesRelatedComplex[1].Comments[0],
-			articlesRelatedComplex[2].Comments[0],
-			articlesRelatedComplex[2].Comments[1],
-		),
+		MarshalInclude(articlesRelatedComplexIncluded...),
 	}
 
 	// articles with non-spec-conforming member names
@@ -164,6 +168,7 @@ var (
 	articleAWithMetaBody                  = `{"data":{"id":"1","type":"articles","attributes":{"title":"A"},"meta":{"views":10,"reads":4}}}`
 	articleNullWithToplevelMetaBody       = `{"data":null,"meta":{"foo":"bar"}}`
 	articleEmptyArrayWithToplevelMetaBody = `{"data":[],"meta":{"foo":"bar"}}`
+	nodeBody                              = `{"data":{"type":"nodes","id":"1","attributes":{"label":{"text":"A"}}}}`
 	articleEmbeddedBody                   = `{"data":{"type":"articles","id":"1","attributes":{"title":"A","lastModified":"1989-06-15T00:00:00Z"}}}`
 	commentEmbeddedBody                   = `{"data":{"id":"1","type":"comments","attributes":{"body":"A"},"relationships":{"author":{"data":{"id":"1","type":"author"}}}}}`
 	articleAWithTopLevelLink              = `{"data":{"id":"1","type":"articles","attributes":{"title":"A"}},"links":{"self":"http://example.com/article/1"}}`
@@ -200,6 +205,18 @@ var (
 	articleWithInvalidRelationshipAttributeNameIncludedBody = `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"1","type":"author"}}}},"included":[{"id":"1","type":"author","attributes":{"na%me":"A"}}]}`
 	websiteWithInvalidNestedRelationshipTypeNameBody        = `{"data":{"id":"1","type":"website","relationships":{"articles":{"data":[{"id":"2","type":"articles"},{"id":"1","type":"articles"}]}}},"included":[{"id":"2","type":"articles","relationships":{"author":{"data":null}}},{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"1","type":"aut%hor"}}}},{"id":"1","type":"aut%hor"}]}`
 
+	// polymorphic relationships
+	projectOwnedByUserBody    = `{"data":{"id":"1","type":"projects","relationships":{"owner":{"data":{"id":"1","type":"users"}},"watchers":{"data":[]}}}}`
+	projectOwnedByTeamBody    = `{"data":{"id":"1","type":"projects","relationships":{"owner":{"data":{"id":"1","type":"teams"}},"watchers":{"data":[{"id":"2","type":"users"},{"id":"1","type":"teams"}]}}},"included":[{"id":"1","type":"teams","attributes":{"name":"T"},"relationships":{"members":{"data":[{"id":"2","type":"users"}]}}},{"id":"2","type":"users","attributes":{"name":"B"}}]}`
+	projectOwnedByUnknownBody = `{"data":{"id":"1","type":"projects","relationships":{"owner":{"data":{"id":"1","type":"bots"}}}}}`
+	projectWithoutOwnerBody   = `{"data":{"id":"1","type":"projects","relationships":{"owner":{"data":null}}}}`
+	principalsBody            = `{"data":[{"id":"1","type":"users","attributes":{"name":"A"}},{"id":"1","type":"teams","attributes":{"name":"T"},"relationships":{"members":{"data":[{"id":"2","type":"users"}]}}}],"included":[{"id":"2","type":"users","attributes":{"name":"B"}}]}`
+	principalsWithProjectBody = `{"data":[{"id":"1","type":"users","attributes":{"name":"A"}},{"id":"1","type":"projects"}]}`
+
+	// cyclic relationships
+	bookWithWriterBody  = `{"data":{"id":"1","type":"books","attributes":{"title":"A"},"relationships":{"writer":{"data":{"id":"1","type":"writers"}}}},"included":[{"id":"1","type":"writers","attributes":{"name":"W"},"relationships":{"books":{"data":[{"id":"1","type":"books"},{"id":"2","type":"books"}]}}},{"id":"2","type":"books","attributes":{"title":"B"},"relationships":{"writer":{"data":{"id":"1","type":"writers"}}}}]}`
+	booksWithWriterBody = `{"data":[{"id":"1","type":"books","attributes":{"title":"A"},"relationships":{"writer":{"data":{"id":"1","type":"writers"}}}},{"id":"2","type":"books","attributes":{"title":"B"},"relationships":{"writer":{"data":{"id":"1","type":"writers"}}}}],"included":[{"id":"1","type":"writers","attributes":{"name":"W"},"relationships":{"books":{"data":[{"id":"1","type":"books"},{"id":"2","type":"books"}]}}}]}`
+
 	// error structs
 	errorsSimpleStruct         = Error{Title: "T"}           //nolint: errname
 	errorsSimpleSliceSingle    = []Error{errorsSimpleStruct} //nolint: errname
@@ -512,6 +529,19 @@ type ArticleEmbeddedPointer struct {
 	Title string `jsonapi:"attribute" json:"title"`
 }
 
+// Node embeds itself, which must not recurse forever when its fields are computed.
+type Node struct {
+	ID    string `jsonapi:"primary,nodes"`
+	Label Label  `jsonapi:"attribute" json:"label"`
+	*Node
+}
+
+// Label embeds itself, which must not recurse forever when its member names are validated.
+type Label struct {
+	Text string `json:"text"`
+	*Label
+}
+
 type ArticleWithGenericMeta struct {
 	ID   string         `jsonapi:"primary,articles"`
 	Meta map[string]any `jsonapi:"meta"`
@@ -558,3 +588,103 @@ type WebsiteWithInvalidNestedRelationshipTypeName struct {
 	ID       string                                    `jsonapi:"primary,website"`
 	Articles []*ArticleWithInvalidRelationshipTypeName `jsonapi:"relationship" json:"articles"`
 }
+
+func makeArticlesLarge(n int) []*ArticleRelated {
+	articles := make([]*ArticleRelated, n)
+	for i := range articles {
+		id := strconv.Itoa(i + 1)
+		articles[i] = &ArticleRelated{
+			ID:       id,
+			Title:    "Article " + id,
+			Author:   &Author{ID: strconv.Itoa(i%10 + 1)},
+			Comments: []*Comment{{ID: id + "1"}, {ID: id + "2"}},
+		}
+	}
+	return articles
+}
+
+// Principal is implemented by the resources which can own a Project.
+type Principal interface {
+	PrincipalName() string
+}
+
+type User struct {
+	ID   string `jsonapi:"primary,users"`
+	Name string `jsonapi:"attribute" json:"name"`
+}
+
+func (u User) PrincipalName() string {
+	return u.Name
+}
+
+type Team struct {
+	ID      string      `jsonapi:"primary,teams"`
+	Name    string      `jsonapi:"attribute" json:"name"`
+	Members []Principal `jsonapi:"relationship" json:"members,omitempty"`
+}
+
+func (t *Team) PrincipalName() string {
+	return t.Name
+}
+
+type Project struct {
+	ID       string      `jsonapi:"primary,projects"`
+	Owner    Principal   `jsonapi:"relationship" json:"owner,omitempty"`
+	Watchers []Principal `jsonapi:"relationship" json:"watchers,omitempty"`
+}
+
+type ArticlePatch struct {
+	ID       string             `jsonapi:"primary,articles"`
+	Title    Optional[string]   `jsonapi:"attribute" json:"title"`
+	Subtitle Nullable[string]   `jsonapi:"attribute" json:"subtitle"`
+	Tags     Nullable[[]string] `jsonapi:"attribute" json:"tags,omitempty"`
+}
+
+type ArticlePatchPointers struct {
+	ID       string            `jsonapi:"primary,articles"`
+	Title    *Optional[string] `jsonapi:"attribute" json:"title"`
+	Subtitle *Nullable[string] `jsonapi:"attribute" json:"subtitle"`
+}
+
+// ArticlePatchBuilder marshals the attributes of an ArticlePatchPointers with a ResourceBuilder.
+type ArticlePatchBuilder ArticlePatchPointers
+
+// MarshalJSONAPIResource implements the ResourceMarshaler interface.
+func (a *ArticlePatchBuilder) MarshalJSONAPIResource(b *ResourceBuilder) error {
+	if err := b.Primary("articles", a.ID); err != nil {
+		return err
+	}
+	b.Attribute("title", a.Title)
+	b.Attribute("subtitle", a.Subtitle)
+	return nil
+}
+
+type ArticleWithPresence struct {
+	ID      string          `jsonapi:"primary,articles"`
+	Title   string          `jsonapi:"attribute" json:"title"`
+	Body    string          `jsonapi:"attribute" json:"body"`
+	Author  *Author         `jsonapi:"relationship" json:"author"`
+	Present map[string]bool `jsonapi:"presence" json:"-"`
+}
+
+type Event struct {
+	ID       string     `jsonapi:"primary,events"`
+	Name     string     `jsonapi:"attribute" json:"name"`
+	Priority int        `jsonapi:"attribute" json:"priority"`
+	Done     bool       `jsonapi:"attribute" json:"done"`
+	Start    *time.Time `jsonapi:"attribute" json:"start"`
+	Tags     []string   `jsonapi:"attribute" json:"tags"`
+	Author   *Author    `jsonapi:"relationship" json:"author"`
+}
+
+type Book struct {
+	ID     string  `jsonapi:"primary,books"`
+	Title  string  `jsonapi:"attribute" json:"title"`
+	Writer *Writer `jsonapi:"relationship" json:"writer,omitempty"`
+}
+
+type Writer struct {
+	ID    string  `jsonapi:"primary,writers"`
+	Name  string  `jsonapi:"attribute" json:"name"`
+	Books []*Book `jsonapi:"relationship" json:"books,omitempty"`
+}
@@ -1,35 +1,41 @@
 package jsonapi
 
 import (
-	"encoding"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+
+	"github.com/DataDog/jsonapi/internal/schema"
 )
 
 var fieldsQueryRegex *regexp.Regexp
 
 func init() {
-	fieldsQueryRegex = regexp.MustCompile(`^fields\[(\w+)\]$`)
+	fieldsQueryRegex = regexp.MustCompile(`^fields\[([^\[\]]+)\]$`)
 }
 
-// Marshaler is configured internally via MarshalOption's passed to Marshal.
+// Marshaler is configured via MarshalOption's, either passed to Marshal or to NewMarshaler.
 // It's used to configure the Marshaling by including optional fields like Meta or JSONAPI.
+//
+// A Marshaler is safe for concurrent use by multiple goroutines.
 type Marshaler struct {
 	meta                     any
 	includeJSONAPI           bool
 	checkUniqueness          bool
 	jsonAPImeta              any
 	included                 []any
+	includePaths             [][]string
 	link                     *Link
 	clientMode               bool
 	memberNameValidationMode MemberNameValidationMode
 
 	// fields support sparse fieldsets https://jsonapi.org/format/#fetching-sparse-fieldsets
-	fields map[string][]string
+	fields      map[string][]string
+	checkFields bool
 }
 
 // MarshalOption allows for configuration of Marshaling.
@@ -80,6 +86,24 @@ func MarshalFields(query url.Values) MarshalOption {
 	}
 }
 
+// MarshalCheckFields enables validation of the sparse fieldsets of MarshalFields, which must only name
+// resource types and fields of the values being marshaled: the primary data, the values of
+// MarshalInclude, and the resources related to them. Otherwise, marshaling returns an *Error like
+// the ones of ParseQuery, pointing at the fields[TYPE] parameter, which can be marshaled as the
+// response.
+//
+// The resource types are found by following the relationship fields of the marshaled types, and
+// the values of relationships holding interfaces. The fields of a *Resource are the attributes and
+// relationships it holds, and the types of its relationship linkage accept any field.
+//
+// Documents written with Encoder.Stream or Encoder.EncodeEach aren't validated, since the types of
+// their resources aren't known before the primary data is written.
+func MarshalCheckFields() MarshalOption {
+	return func(m *Marshaler) {
+		m.checkFields = true
+	}
+}
+
 // MarshalLinks includes the given links as Document.Links when marshaling.
 func MarshalLinks(l *Link) MarshalOption {
 	return func(m *Marshaler) {
@@ -112,8 +136,43 @@ func (m *Marshaler) relationshipMarshaler(link *Link) *Marshaler {
 	return rm
 }
 
+// NewMarshaler returns a Marshaler configured with the given options, meant to be created once and
+// reused for every document sharing the same configuration (e.g. name validation, json:api meta or
+// links).
+func NewMarshaler(opts ...MarshalOption) *Marshaler {
+	m := new(Marshaler)
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// with returns m when no options are given, otherwise a copy of m with the given options applied.
+func (m *Marshaler) with(opts []MarshalOption) *Marshaler {
+	if len(opts) == 0 {
+		return m
+	}
+
+	cm := *m
+	for _, opt := range opts {
+		opt(&cm)
+	}
+	return &cm
+}
+
 // Marshal returns the json:api encoding of v. If v is type *Error or []*Error only the errors will be marshaled.
-func Marshal(v any, opts ...MarshalOption) (b []byte, err error) {
+func Marshal(v any, opts ...MarshalOption) ([]byte, error) {
+	return NewMarshaler(opts...).marshal(v)
+}
+
+// Marshal returns the json:api encoding of v like the package-level Marshal, using the options m
+// was created with. The given options are applied on top of those for this call only.
+func (m *Marshaler) Marshal(v any, opts ...MarshalOption) ([]byte, error) {
+	return m.with(opts).marshal(v)
+}
+
+// marshal returns the json:api encoding of v using the configuration of m.
+func (m *Marshaler) marshal(v any) (b []byte, err error) {
 	defer func() {
 		// because we make use of reflect we must recover any panics
 		if rvr := recover(); rvr != nil {
@@ -122,11 +181,6 @@ func Marshal(v any, opts ...MarshalOption) (b []byte, err error) {
 		}
 	}()
 
-	m := new(Marshaler)
-	for _, opt := range opts {
-		opt(m)
-	}
-
 	// marshal first constructs a jsonapi.Document
 	// the given "v" is the resource document (either one or many) of any type
 	var d *document
@@ -135,13 +189,12 @@ func Marshal(v any, opts ...MarshalOption) (b []byte, err error) {
 		return
 	}
 
-	// now that we have a document, just marshal it as normal json
-	b, err = json.Marshal(d)
-	if err != nil {
+	if err = d.validateMemberNames(m.memberNameValidationMode, ""); err != nil {
 		return
 	}
 
-	err = validateJSONMemberNames(b, m.memberNameValidationMode)
+	// now that we have a valid document, just marshal it as normal json
+	b, err = json.Marshal(d)
 
 	return
 }
@@ -172,7 +225,7 @@ func makeDocument(v any, m *Marshaler, isRelationship bool) (*document, error) {
 	case vt == nil:
 		// if v is nil we want `{"data":null}` so continue with an empty document
 		break
-	case derefType(vt).Kind() == reflect.Slice:
+	case schema.DerefType(vt).Kind() == reflect.Slice:
 		// if we get a slice we make a resource object for each item
 		d.hasMany = true
 		// if v is an empty slice we want `{"data":[]}`
@@ -190,7 +243,7 @@ func makeDocument(v any, m *Marshaler, isRelationship bool) (*document, error) {
 				d.DataMany = append(d.DataMany, ro)
 			}
 		}
-	case derefType(vt).Kind() == reflect.Struct:
+	case schema.DerefType(vt).Kind() == reflect.Struct:
 		if reflect.ValueOf(v).IsZero() {
 			break
 		}
@@ -212,6 +265,20 @@ func makeDocument(v any, m *Marshaler, isRelationship bool) (*document, error) {
 		}
 		d.Included = append(d.Included, ro)
 	}
+	if len(m.includePaths) > 0 {
+		seen := make(map[string]bool)
+		for _, ro := range d.getResourceObjectSlice() {
+			seen[ro.getIdentifier()] = true
+		}
+		for _, ro := range d.Included {
+			seen[ro.getIdentifier()] = true
+		}
+		included, err := d.includePathResources(v, m, seen)
+		if err != nil {
+			return nil, err
+		}
+		d.Included = append(d.Included, included...)
+	}
 	if m.checkUniqueness {
 		if ok := d.verifyResourceUniqueness(); !ok {
 			return nil, ErrNonuniqueResource
@@ -222,6 +289,11 @@ func makeDocument(v any, m *Marshaler, isRelationship bool) (*document, error) {
 		return nil, err
 	}
 
+	if m.checkFields {
+		if err := checkDocumentFieldsets(v, m); err != nil {
+			return nil, err
+		}
+	}
 	filterDocumentFieldsets(d, m)
 
 	if err := addOptionalDocumentFields(d, m); err != nil {
@@ -274,6 +346,184 @@ func filterDocumentFieldsets(d *document, m *Marshaler) {
 	}
 }
 
+// checkDocumentFieldsets returns an error if the sparse fieldsets of MarshalFields name resource
+// types or fields which aren't those of v, the values of MarshalInclude, or the resources related
+// to them.
+func checkDocumentFieldsets(v any, m *Marshaler) error {
+	if len(m.fields) == 0 {
+		return nil
+	}
+
+	fm := &fieldsetMembers{
+		members:  make(map[string]map[string]bool),
+		types:    make(map[reflect.Type]bool),
+		pointers: make(map[uintptr]bool),
+	}
+	fm.addValue(reflect.ValueOf(v))
+	for _, iv := range m.included {
+		fm.addValue(reflect.ValueOf(iv))
+	}
+
+	// fieldsets are checked in order so that the same error is returned for the same query
+	types := make([]string, 0, len(m.fields))
+	for typ := range m.fields {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+
+	for _, typ := range types {
+		parameter := "fields[" + typ + "]"
+		members, ok := fm.members[typ]
+		if !ok {
+			return queryError(parameter, "%q is not a resource type", typ)
+		}
+		for _, name := range m.fields[typ] {
+			// an empty fieldset (e.g. "fields[articles]=") is valid, and nil members accept any field
+			if name != "" && members != nil && !members[name] {
+				return queryError(parameter, "%q is not a field of %q", name, typ)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldsetMembers gathers the attribute and relationship member names of resource types.
+type fieldsetMembers struct {
+	// members holds the member names by resource type, which are nil for types whose members are
+	// unknown (e.g. the relationship linkage of a *Resource)
+	members map[string]map[string]bool
+
+	// types and pointers hold the visited struct types and pointers
+	types    map[reflect.Type]bool
+	pointers map[uintptr]bool
+}
+
+// addValue adds the resource types of rv, and of the resources related to it, including those held
+// by relationships of interface types.
+func (fm *fieldsetMembers) addValue(rv reflect.Value) {
+	if rv.IsValid() && rv.Type() == reflect.TypeOf((*Resource)(nil)) {
+		if !rv.IsNil() {
+			fm.addResource(rv.Interface().(*Resource))
+		}
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Interface:
+		if !rv.IsNil() {
+			fm.addValue(rv.Elem())
+		}
+	case reflect.Pointer:
+		if rv.IsNil() || fm.pointers[rv.Pointer()] {
+			fm.addType(rv.Type())
+			return
+		}
+		fm.pointers[rv.Pointer()] = true
+		fm.addValue(rv.Elem())
+	case reflect.Slice, reflect.Array:
+		fm.addType(rv.Type())
+		for i := 0; i < rv.Len(); i++ {
+			fm.addValue(rv.Index(i))
+		}
+	case reflect.Struct:
+		fm.addType(rv.Type())
+
+		fields, err := schema.CachedTypeFields(rv.Type())
+		if err != nil {
+			return
+		}
+		for i := range fields {
+			f := &fields[i]
+			if f.Tag.Directive != schema.Relationship || !holdsInterface(f.Type) {
+				continue
+			}
+			if fv, ok := schema.FieldByIndex(rv, f.Index, false); ok {
+				fm.addValue(fv)
+			}
+		}
+	}
+}
+
+// addType adds the resource type of t, a struct or a pointer or slice holding one, and the resource
+// types of its relationship fields.
+func (fm *fieldsetMembers) addType(t reflect.Type) {
+	t = schema.DerefType(t)
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = schema.DerefType(t.Elem())
+	}
+	if t.Kind() != reflect.Struct || fm.types[t] {
+		return
+	}
+	fm.types[t] = true
+
+	fields, err := schema.CachedTypeFields(t)
+	if err != nil {
+		return
+	}
+
+	var resourceType string
+	names := make(map[string]bool)
+	for i := range fields {
+		f := &fields[i]
+		switch f.Tag.Directive {
+		case schema.Primary:
+			resourceType = f.Tag.ResourceType
+		case schema.Attribute, schema.Relationship:
+			if f.Exported {
+				names[f.MemberName] = true
+			}
+		}
+		if f.Tag.Directive == schema.Relationship {
+			fm.addType(f.Type)
+		}
+	}
+	if resourceType != "" {
+		fm.addMembers(resourceType, names)
+	}
+}
+
+// addResource adds the resource type and members of r, and the resource types of its relationship
+// linkage, whose members are unknown.
+func (fm *fieldsetMembers) addResource(r *Resource) {
+	names := make(map[string]bool)
+	for name := range r.ro.Attributes {
+		names[name] = true
+	}
+	for name, rd := range r.ro.Relationships {
+		names[name] = true
+		for _, ro := range rd.getResourceObjectSlice() {
+			fm.addMembers(ro.Type, nil)
+		}
+	}
+	fm.addMembers(r.ro.Type, names)
+}
+
+// addMembers adds the member names of the given resource type, or marks its members as unknown
+// if names is nil.
+func (fm *fieldsetMembers) addMembers(resourceType string, names map[string]bool) {
+	members, ok := fm.members[resourceType]
+	switch {
+	case !ok || (members == nil && names != nil):
+		// known members replace unknown ones
+		fm.members[resourceType] = names
+	case members != nil:
+		// several types may share a resource type
+		for name := range names {
+			members[name] = true
+		}
+	}
+}
+
+// holdsInterface returns true if t is an interface, or a pointer or slice holding one.
+func holdsInterface(t reflect.Type) bool {
+	t = schema.DerefType(t)
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = schema.DerefType(t.Elem())
+	}
+	return t.Kind() == reflect.Interface
+}
+
 func makeDocumentErrors(v any, m *Marshaler) (*document, error) {
 	var errorObjects []*Error
 
@@ -323,7 +573,7 @@ func (d *document) makeResourceObject(v any, vt reflect.Type, m *Marshaler) (*re
 	// the given "v" here is a single resource object
 
 	// first, it must be a struct since we'll be parsing the jsonapi struct tags
-	if derefType(vt).Kind() != reflect.Struct {
+	if schema.DerefType(vt).Kind() != reflect.Struct {
 		return nil, &TypeError{Actual: vt.String(), Expected: []string{"struct"}}
 	}
 
@@ -331,135 +581,118 @@ func (d *document) makeResourceObject(v any, vt reflect.Type, m *Marshaler) (*re
 		Attributes:    make(map[string]any, 0),
 		Relationships: make(map[string]*document, 0),
 	}
+	ro.structType = schema.DerefType(vt)
 
-	// get fields from embedded structs
-	fields := getFlattenedFields(v)
-
-	var foundPrimary bool
-	for _, field := range fields {
-		// for each field in the struct we'll parse the jsonapi struct tag
-		// this will determine where it goes in the resource object (e.g. id,type,attributes,...)
-
-		f := field.v
-		ft := field.f
+	// resources without Go types hold their resource object already
+	if r, ok := v.(*Resource); ok {
+		return d.makeDynamicResourceObject(r, m)
+	}
 
-		tag, err := parseJSONAPITag(ft)
-		if err != nil {
+	// generated code builds the resource object without inspecting the struct tags
+	if rm, ok := v.(ResourceMarshaler); ok {
+		b := &ResourceBuilder{v: v, ro: ro, d: d, m: m}
+		if err := rm.MarshalJSONAPIResource(b); err != nil {
 			return nil, err
 		}
-		if tag == nil {
-			// this field is not tagged w/ jsonapi and will be ignored
-			continue
-		}
-
-		switch tag.directive {
-		case primary:
-			ro.Type = tag.resourceType
-			if !isValidMemberName(ro.Type, m.memberNameValidationMode) {
-				// type names count as member names
-				return nil, &MemberNameValidationError{ro.Type}
-			}
-
-			// to marshal the id we follow these rules
-			//     1. Use MarshalIdentifier if it is implemented
-			//     2. Use the value directly if it is a string
-			//     3. Use fmt.Stringer if it is implemented
-			//     4. Use encoding.TextMarshaler if it is implemented
-			//     5. Fail
-
-			if vm, ok := v.(MarshalIdentifier); ok {
-				ro.ID = vm.MarshalID()
-				foundPrimary = true
-				continue
-			}
+		return d.completeResourceObject(v, ro, b.foundPrimary, m)
+	}
 
-			fv := f.Interface()
+	// get the tagged fields, including those from embedded structs
+	rv := derefValue(reflect.ValueOf(v))
+	fields, err := schema.CachedTypeFields(rv.Type())
+	if err != nil {
+		return nil, err
+	}
 
-			if vs, ok := fv.(string); ok {
-				ro.ID = vs
-				foundPrimary = true
-				continue
-			}
+	var foundPrimary bool
+	for i := range fields {
+		// for each tagged field in the struct the jsonapi struct tag determines where it goes
+		// in the resource object (e.g. id,type,attributes,...)
+		tag := fields[i].Tag
 
-			if _, ok := fv.(fmt.Stringer); ok {
-				ro.ID = fmt.Sprintf("%s", fv)
-				foundPrimary = true
-				continue
-			}
+		f, ok := schema.FieldByIndex(rv, fields[i].Index, false)
+		if !ok {
+			// this field belongs to a nil embedded struct pointer
+			continue
+		}
 
-			if fvm, ok := fv.(encoding.TextMarshaler); ok {
-				vb, err := fvm.MarshalText()
-				if err != nil {
-					return nil, err
-				}
-				ro.ID = string(vb)
-				foundPrimary = true
-				continue
+		switch tag.Directive {
+		case schema.Primary:
+			ro.Type = tag.ResourceType
+			ro.ID, err = schema.MarshalPrimary(v, f.Interface())
+			if err != nil {
+				return nil, err
 			}
-
-			return nil, ErrMarshalInvalidPrimaryField
-		case attribute:
+			foundPrimary = true
+		case schema.Attribute:
 			if d.isRelationship {
 				// relationships must only be resource identifier objects so skip attributes
 				continue
 			}
-			fieldName, ok, omit := parseJSONTag(ft)
-			if !ok {
+			if !fields[i].Exported {
 				continue
 			}
-			if f.IsZero() && omit {
+			if f.IsZero() && fields[i].OmitEmpty || isAbsent(f.Interface()) {
 				continue
 			}
-			ro.Attributes[fieldName] = f.Interface()
-		case meta:
-			metaObject := f.Interface()
-			if err := checkMeta(metaObject); err != nil {
+			ro.Attributes[fields[i].MemberName] = f.Interface()
+		case schema.Meta:
+			if err := d.marshalMeta(ro, f.Interface(), f.IsZero(), m); err != nil {
 				return nil, err
 			}
-
-			if f.IsZero() {
-				// ensure json omitempty works correctly with meta any type
-				metaObject = nil
-			}
-
-			if d.isRelationship {
-				// let meta become document-level for relationships (treated as nested documents)
-				m.meta = metaObject
-			} else {
-				ro.Meta = metaObject
-			}
-		case relationship:
+		case schema.Relationship:
 			if d.isRelationship {
 				// relationship nesting must occur in include data, not the relationship fields
 				continue
 			}
-			fieldName, ok, omit := parseJSONTag(ft)
-			if !ok {
+			if !fields[i].Exported {
 				continue
 			}
-			if f.IsZero() && omit {
+			if f.IsZero() && fields[i].OmitEmpty {
 				continue
 			}
-
-			// if LinkableRelation is implemented include Document.Links for the related resource
-			var link *Link
-			if lv, ok := v.(LinkableRelation); ok {
-				link = lv.LinkRelation(fieldName)
-				if err := link.check(); err != nil {
-					return nil, err
-				}
+			if err := d.marshalRelationship(v, ro, fields[i].MemberName, f.Interface(), m); err != nil {
+				return nil, err
 			}
+		}
+	}
 
-			rm := m.relationshipMarshaler(link)
-			d, err := makeDocument(f.Interface(), rm, true)
-			if err != nil {
+	return d.completeResourceObject(v, ro, foundPrimary, m)
+}
+
+// makeDynamicResourceObject returns the resource object of r, or its resource identifier object
+// if d is a relationship.
+func (d *document) makeDynamicResourceObject(r *Resource, m *Marshaler) (*resourceObject, error) {
+	if d.isRelationship {
+		return d.completeResourceObject(r, &resourceObject{Type: r.ro.Type, ID: r.ro.ID}, r.ro.Type != "", m)
+	}
+
+	// copy the resource object so that marshaling (e.g. sparse fieldsets) doesn't modify r
+	ro := *r.ro
+	if err := checkMeta(ro.Meta); err != nil {
+		return nil, err
+	}
+	if ro.Links != nil {
+		// the links object is copied as well since checking it may modify it
+		link := *ro.Links
+		ro.Links = &link
+
+		switch {
+		case !r.parsedLinks:
+			if err := ro.Links.check(); err != nil {
 				return nil, err
 			}
-
-			ro.Relationships[fieldName] = d
+		case reflect.ValueOf(link).IsZero():
+			// parsed links without any link Link holds are left out
+			ro.Links = nil
 		}
 	}
 
+	return d.completeResourceObject(r, &ro, ro.Type != "", m)
+}
+
+// completeResourceObject checks the primary field of ro and adds the resource links of v.
+func (d *document) completeResourceObject(v any, ro *resourceObject, foundPrimary bool, m *Marshaler) (*resourceObject, error) {
 	// primary is the only required jsonapi struct tag as it defines the id/type
 	if !foundPrimary {
 		return nil, ErrMissingPrimaryField
@@ -482,33 +715,46 @@ func (d *document) makeResourceObject(v any, vt reflect.Type, m *Marshaler) (*re
 	return ro, nil
 }
 
-func getFlattenedFields(iface interface{}) []struct {
-	v reflect.Value
-	f reflect.StructField
-} {
-	rv := derefValue(reflect.ValueOf(iface))
-	rt := reflect.TypeOf(rv.Interface())
+// marshalMeta sets the meta of ro, or of the relationship document when d is one.
+func (d *document) marshalMeta(ro *resourceObject, metaObject any, isZero bool, m *Marshaler) error {
+	if err := checkMeta(metaObject); err != nil {
+		return err
+	}
 
-	fields := make([]struct {
-		v reflect.Value
-		f reflect.StructField
-	}, 0)
+	if isZero {
+		// ensure json omitempty works correctly with meta any type
+		metaObject = nil
+	}
 
-	for i := 0; i < rv.NumField(); i++ {
-		v := rv.Field(i)
-		f := rt.Field(i)
+	if d.isRelationship {
+		// let meta become document-level for relationships (treated as nested documents)
+		m.meta = metaObject
+	} else {
+		ro.Meta = metaObject
+	}
 
-		if f.Anonymous && (v.Kind() == reflect.Struct || v.Kind() == reflect.Pointer) {
-			fields = append(fields, getFlattenedFields(v.Interface())...)
-		} else {
-			fields = append(fields, struct {
-				v reflect.Value
-				f reflect.StructField
-			}{v, f})
+	return nil
+}
+
+// marshalRelationship adds the relationship named name holding related to the resource object ro of v.
+func (d *document) marshalRelationship(v any, ro *resourceObject, name string, related any, m *Marshaler) error {
+	// if LinkableRelation is implemented include Document.Links for the related resource
+	var link *Link
+	if lv, ok := v.(LinkableRelation); ok {
+		link = lv.LinkRelation(name)
+		if err := link.check(); err != nil {
+			return err
 		}
 	}
 
-	return fields
+	rm := m.relationshipMarshaler(link)
+	rd, err := makeDocument(related, rm, true)
+	if err != nil {
+		return err
+	}
+
+	ro.Relationships[name] = rd
+	return nil
 }
 
 func addOptionalDocumentFields(d *document, m *Marshaler) error {
@@ -520,7 +766,7 @@ func addOptionalDocumentFields(d *document, m *Marshaler) error {
 
 	// optionally include the Document.jsonapi (may be nil, which will be omitted)
 	if m.includeJSONAPI {
-		d.JSONAPI = &jsonAPI{Version: "1.0"}
+		d.JSONAPI = &JSONAPIObject{Version: "1.0"}
 		if err := checkMeta(m.jsonAPImeta); err != nil {
 			return err
 		}
@@ -64,3 +64,23 @@ func ExampleMarshal_meta() {
 	fmt.Printf("%s", string(b))
 	// Output: {"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"},"meta":{"views":10}},"meta":{"foo":"bar"}}
 }
+
+func ExampleNewMarshaler() {
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	// created once, e.g. when starting a server, and shared by all requests
+	m := jsonapi.NewMarshaler(jsonapi.MarshalJSONAPI(map[string]any{"service": "blog"}))
+
+	a := Article{ID: "1", Title: "Hello World"}
+
+	b, err := m.Marshal(&a, jsonapi.MarshalLinks(&jsonapi.Link{Self: "http://example.com/articles/1"}))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s", string(b))
+	// Output: {"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"}},"jsonapi":{"version":"1.0","meta":{"service":"blog"}},"links":{"self":"http://example.com/articles/1"}}
+}
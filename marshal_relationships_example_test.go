@@ -59,3 +59,24 @@ func ExampleMarshal_relationships() {
 	fmt.Printf("%s", string(b))
 	// Output: {"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"},"relationships":{"author":{"data":{"id":"AA","type":"author"},"links":{"self":"http://example.com/articles/1/relationships/author","related":"http://example.com/articles/1/author"}},"comments":{"data":[{"id":"CA","type":"comments"},{"id":"CB","type":"comments"}],"links":{"self":"http://example.com/articles/1/relationships/comments","related":"http://example.com/articles/1/comments"}}}}}
 }
+
+func ExampleMarshalIncludePaths() {
+	author := &Author{ID: "AA", Name: "Cool Author"}
+	article := Article{
+		ID:       "1",
+		Title:    "Hello World",
+		Author:   author,
+		Comments: []*Comment{{ID: "CA", Body: "Very cool", Author: author}},
+	}
+
+	// e.g. the value of the "include" query parameter of the request
+	include := "author,comments"
+
+	b, err := jsonapi.Marshal(&article, jsonapi.MarshalIncludePaths(include))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s", string(b))
+	// Output: {"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"},"relationships":{"author":{"data":{"id":"AA","type":"author"},"links":{"self":"http://example.com/articles/1/relationships/author","related":"http://example.com/articles/1/author"}},"comments":{"data":[{"id":"CA","type":"comments"}],"links":{"self":"http://example.com/articles/1/relationships/comments","related":"http://example.com/articles/1/comments"}}}},"included":[{"id":"AA","type":"author","attributes":{"name":"Cool Author"}},{"id":"CA","type":"comments","attributes":{"comment":"Very cool"},"relationships":{"Author":{"data":{"id":"AA","type":"author"},"links":{"self":"http://example.com/comments/CA/relationships/Author","related":"http://example.com/comments/CA/Author"}}}}]}
+}
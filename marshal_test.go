@@ -222,6 +222,11 @@ func TestMarshal(t *testing.T) {
 			given:       &articleEmbeddedPointer,
 			expect:      articleEmbeddedBody,
 			expectError: nil,
+		}, {
+			description: "ArticleEmbeddedPointer (nil)",
+			given:       &ArticleEmbeddedPointer{ID: "1", Title: "A"},
+			expect:      articleABody,
+			expectError: nil,
 		}, {
 			description: "Error simple",
 			given:       errorsSimpleStruct,
@@ -277,6 +282,11 @@ func TestMarshal(t *testing.T) {
 			given:       errorsWithInvalidLinkMeta,
 			expect:      "",
 			expectError: &TypeError{Actual: "string", Expected: []string{"struct", "map"}},
+		}, {
+			description: "Node (recursive embedding)",
+			given:       Node{ID: "1", Label: Label{Text: "A"}},
+			expect:      nodeBody,
+			expectError: nil,
 		}, {
 			description: "Error empty",
 			given:       Error{},
@@ -540,6 +550,90 @@ func TestMarshalFields(t *testing.T) {
 	}
 }
 
+func TestMarshalCheckFields(t *testing.T) {
+	t.Parallel()
+
+	article := &ArticleRelated{ID: "1", Title: "A", Author: &Author{ID: "1", Name: "B"}}
+
+	resource := NewResource("articles", "1")
+	resource.SetAttribute("title", "A")
+	resource.SetRelationship("author", NewToOneRelationship(&ResourceIdentifier{Type: "people", ID: "1"}))
+
+	tests := []struct {
+		description string
+		given       any
+		query       string
+		opts        []MarshalOption
+		expectError error
+	}{
+		{
+			description: "fields of related types",
+			given:       article,
+			query:       "fields[articles]=title,comments&fields[author]=name&fields[comments]=body,author",
+		}, {
+			description: "empty fieldset",
+			given:       article,
+			query:       "fields[articles]=",
+		}, {
+			description: "related types of an empty slice",
+			given:       []*ArticleRelated{},
+			query:       "fields[comments]=archived",
+		}, {
+			description: "types of interface values",
+			given:       []any{article},
+			query:       "fields[articles]=author&fields[author]=name",
+		}, {
+			description: "type of an included value",
+			given:       article,
+			query:       "fields[author]=name",
+			opts:        []MarshalOption{MarshalInclude(article.Author)},
+		}, {
+			description: "unknown field",
+			given:       article,
+			query:       "fields[articles]=title,body",
+			expectError: queryError("fields[articles]", `"body" is not a field of "articles"`),
+		}, {
+			description: "meta is not a field",
+			given:       article,
+			query:       "fields[author]=Meta",
+			expectError: queryError("fields[author]", `"Meta" is not a field of "author"`),
+		}, {
+			description: "members of a resource and the types of its linkage",
+			given:       resource,
+			query:       "fields[articles]=title,author&fields[people]=name",
+		}, {
+			description: "unknown member of a resource",
+			given:       resource,
+			query:       "fields[articles]=body",
+			expectError: queryError("fields[articles]", `"body" is not a field of "articles"`),
+		}, {
+			description: "unknown type",
+			given:       article,
+			query:       "fields[articles]=title&fields[people]=name",
+			expectError: queryError("fields[people]", `"people" is not a resource type`),
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			query, err := url.ParseQuery(tc.query)
+			is.MustNoError(t, err)
+
+			opts := append([]MarshalOption{MarshalFields(query), MarshalCheckFields()}, tc.opts...)
+			_, err = Marshal(tc.given, opts...)
+			if tc.expectError != nil {
+				is.Equal(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+		})
+	}
+}
+
 func TestMarshalRelationships(t *testing.T) {
 	t.Parallel()
 
@@ -706,6 +800,49 @@ func TestMarshalClientMode(t *testing.T) {
 // TestMarshalMemberNameValidation collects tests which verify that invalid member names are caught
 // during marshaling, no matter where they're placed. This test does not exhaustively test every
 // possible invalid name.
+func TestMarshaler(t *testing.T) {
+	t.Parallel()
+
+	m := NewMarshaler(
+		MarshalJSONAPI(map[string]any{"foo": "bar"}),
+		MarshalLinks(&Link{Self: "http://example.com/articles"}),
+	)
+
+	tests := []struct {
+		description string
+		opts        []MarshalOption
+		expect      string
+	}{
+		{
+			description: "options given to NewMarshaler",
+			expect:      `{"data":{"id":"1","type":"articles","attributes":{"title":"A"}},"jsonapi":{"version":"1.0","meta":{"foo":"bar"}},"links":{"self":"http://example.com/articles"}}`,
+		}, {
+			description: "per-call options are added",
+			opts:        []MarshalOption{MarshalMeta(map[string]any{"count": 1})},
+			expect:      `{"data":{"id":"1","type":"articles","attributes":{"title":"A"}},"meta":{"count":1},"jsonapi":{"version":"1.0","meta":{"foo":"bar"}},"links":{"self":"http://example.com/articles"}}`,
+		}, {
+			description: "per-call options override",
+			opts:        []MarshalOption{MarshalLinks(&Link{Self: "http://example.com/articles/1"})},
+			expect:      `{"data":{"id":"1","type":"articles","attributes":{"title":"A"}},"jsonapi":{"version":"1.0","meta":{"foo":"bar"}},"links":{"self":"http://example.com/articles/1"}}`,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			// the subtests run in parallel with the same Marshaler
+			for j := 0; j < 10; j++ {
+				actual, err := m.Marshal(&articleA, tc.opts...)
+				is.MustNoError(t, err)
+				is.EqualJSON(t, tc.expect, string(actual))
+			}
+		})
+	}
+}
+
 func TestMarshalMemberNameValidation(t *testing.T) {
 	t.Parallel()
 
@@ -722,37 +859,44 @@ func TestMarshalMemberNameValidation(t *testing.T) {
 		}, {
 			description: "Author with invalid type name",
 			given:       &authorWithInvalidTypeName,
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/data/type"},
 		}, {
 			description: "Author with invalid attribute name",
 			given:       &authorWithInvalidAttributeName,
-			expectError: &MemberNameValidationError{"na%me"},
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/data/attributes/na%me"},
+		}, {
+			description: "Article with invalid nested attribute member name",
+			given: &struct {
+				ID   string         `jsonapi:"primary,articles"`
+				Info map[string]any `jsonapi:"attribute" json:"info"`
+			}{ID: "1", Info: map[string]any{"tags": []any{map[string]any{"foo%": 1}}}},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/data/attributes/info/tags/0/foo%"},
 		}, {
 			description: "Article with invalid resource meta member name",
 			given:       &articleWithInvalidResourceMetaMemberName,
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/data/meta/foo%"},
 		}, {
 			description:       "Article with invalid top-level meta member name",
 			given:             &articleA,
-			expectError:       &MemberNameValidationError{"foo%"},
+			expectError:       &MemberNameValidationError{MemberName: "foo%", Path: "/meta/foo%"},
 			additionalOptions: []MarshalOption{MarshalMeta(map[string]any{"foo%": 2})},
 		}, {
 			description:       "Article with invalid jsonapi meta member name",
 			given:             &articleA,
-			expectError:       &MemberNameValidationError{"foo%"},
+			expectError:       &MemberNameValidationError{MemberName: "foo%", Path: "/jsonapi/meta/foo%"},
 			additionalOptions: []MarshalOption{MarshalJSONAPI(map[string]any{"foo%": 1})},
 		}, {
 			description: "Article with invalid link meta member name",
 			given:       &articleWithInvalidLinkMetaMemberName,
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/data/links/self/meta/foo%"},
 		}, {
 			description: "Article with invalid relationship name",
 			given:       &articleWithInvalidRelationshipName,
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/data/relationships/aut%hor"},
 		}, {
 			description: "Article with invalid relationship type name",
 			given:       &articleWithInvalidRelationshipTypeName,
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/data/relationships/author/data/type"},
 		}, {
 			description: "Article with invalid relationship attribute name not included",
 			given:       &articleWithInvalidRelationshipAttributeName,
@@ -760,18 +904,18 @@ func TestMarshalMemberNameValidation(t *testing.T) {
 		}, {
 			description:       "Article with invalid relationship attribute name included",
 			given:             &articleWithInvalidRelationshipAttributeName,
-			expectError:       &MemberNameValidationError{"na%me"},
+			expectError:       &MemberNameValidationError{MemberName: "na%me", Path: "/included/0/attributes/na%me"},
 			additionalOptions: []MarshalOption{MarshalInclude(&authorWithInvalidAttributeName)},
 		}, {
 			description: "Articles with one invalid resource meta member name",
 			given: []*ArticleWithGenericMeta{
 				{ID: "1"}, {ID: "2", Meta: map[string]any{"foo%": 1}},
 			},
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/data/1/meta/foo%"},
 		}, {
 			description: "Website with invalid nested relationship type name",
 			given:       &websiteWithInvalidNestedRelationshipTypeName,
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/included/1/relationships/author/data/type"},
 			additionalOptions: []MarshalOption{
 				MarshalInclude(
 					websiteWithInvalidNestedRelationshipTypeName.Articles[0],
@@ -839,6 +983,10 @@ func BenchmarkMarshal(b *testing.B) {
 				[]MarshalOption{MarshalSetNameValidation(DisableValidation)},
 				articlesRelatedComplexMarshalOptions...,
 			),
+		}, {
+			name:  "ArticlesLarge",
+			given: articlesLarge,
+			opts:  nil,
 		},
 	}
 
@@ -1,9 +1,17 @@
 package jsonapi
 
 import (
+	"encoding"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/jsonapi/internal/schema"
 )
 
 var (
@@ -60,40 +68,507 @@ func isValidMemberName(name string, mode MemberNameValidationMode) bool {
 	}
 }
 
-func validateMapMemberNames(m map[string]any, mode MemberNameValidationMode) error {
-	for member, val := range m {
-		if !isValidMemberName(member, mode) {
-			return &MemberNameValidationError{member}
-		}
-		switch nested := val.(type) {
-		case map[string]any:
-			if err := validateMapMemberNames(nested, mode); err != nil {
+// jsonPointerEscaper escapes member names for use as JSON pointer reference tokens as defined by
+// https://www.rfc-editor.org/rfc/rfc6901#section-3.
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// memberPath returns the JSON pointer to the member with the given name within the object at path.
+func memberPath(path, name string) string {
+	return path + "/" + jsonPointerEscaper.Replace(name)
+}
+
+// indexPath returns the JSON pointer to the element at index i within the array at path.
+func indexPath(path string, i int) string {
+	return path + "/" + strconv.Itoa(i)
+}
+
+// checkMemberName returns a *MemberNameValidationError if the given member of the object at path
+// has an invalid name.
+func checkMemberName(name, path string, mode MemberNameValidationMode) error {
+	if !isValidMemberName(name, mode) {
+		return &MemberNameValidationError{MemberName: name, Path: memberPath(path, name)}
+	}
+	return nil
+}
+
+func validateMemberNames(v any, mode MemberNameValidationMode, path string) error {
+	switch nested := v.(type) {
+	case map[string]any:
+		for member, val := range nested {
+			if err := checkMemberName(member, path, mode); err != nil {
 				return err
 			}
-		case []any:
-			for _, entry := range nested {
-				if subMap, ok := entry.(map[string]any); ok {
-					if err := validateMapMemberNames(subMap, mode); err != nil {
-						return err
-					}
-				}
+			if err := validateMemberNames(val, mode, memberPath(path, member)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, entry := range nested {
+			if err := validateMemberNames(entry, mode, indexPath(path, i)); err != nil {
+				return err
 			}
-		default:
-			continue
 		}
 	}
 	return nil
 }
 
-func validateJSONMemberNames(b []byte, mode MemberNameValidationMode) error {
+// validateJSONMemberNames verifies the member names of the JSON value b, located at path.
+func validateJSONMemberNames(b []byte, mode MemberNameValidationMode, path string) error {
 	// do not unmarshal if validation is disabled
 	if mode == DisableValidation {
 		return nil
 	}
 
-	var m map[string]any
-	if err := json.Unmarshal(b, &m); err != nil {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
 		return fmt.Errorf("unexpected unmarshal failure: %w", err)
 	}
-	return validateMapMemberNames(m, mode)
+	return validateMemberNames(v, mode, path)
+}
+
+// validateMemberNames verifies the member names of a document built for marshaling, without
+// encoding it first.
+func (d *document) validateMemberNames(mode MemberNameValidationMode, path string) error {
+	if mode == DisableValidation {
+		return nil
+	}
+
+	if d.hasMany {
+		for i, ro := range d.DataMany {
+			if err := ro.validateMemberNames(mode, indexPath(path+"/data", i)); err != nil {
+				return err
+			}
+		}
+	} else if d.DataOne != nil {
+		if err := d.DataOne.validateMemberNames(mode, path+"/data"); err != nil {
+			return err
+		}
+	}
+
+	for i, ro := range d.Included {
+		if err := ro.validateMemberNames(mode, indexPath(path+"/included", i)); err != nil {
+			return err
+		}
+	}
+
+	for i, e := range d.Errors {
+		ePath := indexPath(path+"/errors", i)
+		if e.Links != nil {
+			if err := validateValueMemberNames(reflect.ValueOf(e.Links), mode, ePath+"/links"); err != nil {
+				return err
+			}
+		}
+		if err := validateValueMemberNames(reflect.ValueOf(e.Meta), mode, ePath+"/meta"); err != nil {
+			return err
+		}
+	}
+
+	if err := validateValueMemberNames(reflect.ValueOf(d.Meta), mode, path+"/meta"); err != nil {
+		return err
+	}
+	if d.JSONAPI != nil {
+		if err := validateValueMemberNames(reflect.ValueOf(d.JSONAPI.Meta), mode, path+"/jsonapi/meta"); err != nil {
+			return err
+		}
+	}
+	if d.Links != nil {
+		return validateValueMemberNames(reflect.ValueOf(d.Links), mode, path+"/links")
+	}
+
+	return nil
+}
+
+// validateMemberNames verifies the member names of a resource object built for marshaling.
+func (ro *resourceObject) validateMemberNames(mode MemberNameValidationMode, path string) error {
+	if !isValidMemberName(ro.Type, mode) {
+		// type names count as member names
+		return &MemberNameValidationError{MemberName: ro.Type, Path: path + "/type"}
+	}
+
+	// attribute and relationship names only need checking if they were not all validated ahead of
+	// time along with the struct type they come from
+	checkNames := ro.structType == nil || !cachedFieldNamesValid(ro.structType, mode)
+
+	for name, v := range ro.Attributes {
+		if checkNames {
+			if err := checkMemberName(name, path+"/attributes", mode); err != nil {
+				return err
+			}
+		}
+		if err := validateValueMemberNames(reflect.ValueOf(v), mode, memberPath(path+"/attributes", name)); err != nil {
+			return err
+		}
+	}
+
+	for name, rel := range ro.Relationships {
+		if checkNames {
+			if err := checkMemberName(name, path+"/relationships", mode); err != nil {
+				return err
+			}
+		}
+		if err := rel.validateMemberNames(mode, memberPath(path+"/relationships", name)); err != nil {
+			return err
+		}
+	}
+
+	if err := validateValueMemberNames(reflect.ValueOf(ro.Meta), mode, path+"/meta"); err != nil {
+		return err
+	}
+	if ro.Links != nil {
+		return validateValueMemberNames(reflect.ValueOf(ro.Links), mode, path+"/links")
+	}
+
+	return nil
+}
+
+// validateTypeNames verifies the resource types of a parsed document, which count as member names
+// but aren't found among the member names of its JSON encoding.
+func (d *document) validateTypeNames(mode MemberNameValidationMode, path string) error {
+	if mode == DisableValidation {
+		return nil
+	}
+
+	if d.hasMany {
+		for i, ro := range d.DataMany {
+			if err := ro.validateTypeNames(mode, indexPath(path+"/data", i)); err != nil {
+				return err
+			}
+		}
+	} else if d.DataOne != nil {
+		if err := d.DataOne.validateTypeNames(mode, path+"/data"); err != nil {
+			return err
+		}
+	}
+
+	for i, ro := range d.Included {
+		if err := ro.validateTypeNames(mode, indexPath(path+"/included", i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTypeNames verifies the resource types of a parsed resource object and of its resource
+// linkage.
+func (ro *resourceObject) validateTypeNames(mode MemberNameValidationMode, path string) error {
+	if mode == DisableValidation {
+		return nil
+	}
+
+	if !isValidMemberName(ro.Type, mode) {
+		return &MemberNameValidationError{MemberName: ro.Type, Path: path + "/type"}
+	}
+	for name, rel := range ro.Relationships {
+		if err := rel.validateTypeNames(mode, memberPath(path+"/relationships", name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// typeMode is the key of caches holding member name validation results.
+type typeMode struct {
+	t    reflect.Type
+	mode MemberNameValidationMode
+}
+
+var (
+	// fieldNamesValidCache is a map[typeMode]bool reporting whether the attribute and relationship
+	// names of a jsonapi tagged struct type are all valid.
+	fieldNamesValidCache sync.Map
+
+	// staticNamesCache is a map[typeMode]bool reporting whether all member names produced when
+	// encoding a value of a type are known from the type alone, and valid.
+	staticNamesCache sync.Map
+)
+
+// cachedFieldNamesValid returns true if all the attribute and relationship names of the given
+// jsonapi tagged struct type are valid.
+func cachedFieldNamesValid(t reflect.Type, mode MemberNameValidationMode) bool {
+	key := typeMode{t, mode}
+	if valid, ok := fieldNamesValidCache.Load(key); ok {
+		return valid.(bool)
+	}
+
+	fields, err := schema.CachedTypeFields(t)
+	valid := err == nil
+	for i := 0; valid && i < len(fields); i++ {
+		switch fields[i].Tag.Directive {
+		case schema.Attribute, schema.Relationship:
+			valid = isValidMemberName(fields[i].MemberName, mode)
+		}
+	}
+
+	fieldNamesValidCache.Store(key, valid)
+	return valid
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	wrappedAttrType   = reflect.TypeOf((*wrappedAttribute)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+	// jsonFieldsCache is a map[reflect.Type][]jsonField of the struct types seen so far.
+	jsonFieldsCache sync.Map
+)
+
+// jsonField is a field of a struct as encoded by encoding/json.
+type jsonField struct {
+	index     []int
+	name      string
+	typ       reflect.Type
+	omitEmpty bool
+}
+
+// cachedJSONFields returns the fields encoding/json encodes for the given struct type. Unlike
+// encoding/json, conflicting names of promoted fields are not resolved.
+func cachedJSONFields(t reflect.Type) []jsonField {
+	if fields, ok := jsonFieldsCache.Load(t); ok {
+		return fields.([]jsonField)
+	}
+
+	fields, _ := jsonFieldsCache.LoadOrStore(t, computeJSONFields(t, nil, make(map[reflect.Type]bool)))
+	return fields.([]jsonField)
+}
+
+// computeJSONFields returns the fields of the struct type t, reached by the given index sequence.
+// The types being visited are skipped when embedded again, so that recursive embedding terminates.
+func computeJSONFields(t reflect.Type, index []int, visiting map[reflect.Type]bool) []jsonField {
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	fields := make([]jsonField, 0, t.NumField())
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		fieldIndex := make([]int, len(index)+1)
+		copy(fieldIndex, index)
+		fieldIndex[len(index)] = i
+
+		if sf.Anonymous {
+			et := sf.Type
+			if et.Kind() == reflect.Pointer {
+				et = et.Elem()
+			}
+			if !sf.IsExported() && et.Kind() != reflect.Struct {
+				continue
+			}
+			if name == "" && et.Kind() == reflect.Struct {
+				if !visiting[et] {
+					fields = append(fields, computeJSONFields(et, fieldIndex, visiting)...)
+				}
+				continue
+			}
+		} else if !sf.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, jsonField{
+			index:     fieldIndex,
+			name:      name,
+			typ:       sf.Type,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+
+	return fields
+}
+
+// hasStaticMemberNames returns true if all member names found in the encoding of any value of the
+// given type are known from the type alone, and valid. Values of such types need no validation.
+func hasStaticMemberNames(t reflect.Type, mode MemberNameValidationMode) bool {
+	key := typeMode{t, mode}
+	if static, ok := staticNamesCache.Load(key); ok {
+		return static.(bool)
+	}
+
+	static := computeStaticMemberNames(t, mode, make(map[reflect.Type]bool))
+	staticNamesCache.Store(key, static)
+	return static
+}
+
+func computeStaticMemberNames(t reflect.Type, mode MemberNameValidationMode, visiting map[reflect.Type]bool) bool {
+	if wt, ok := wrappedType(t); ok {
+		// encoded as the wrapped value, or null
+		return computeStaticMemberNames(wt, mode, visiting)
+	}
+
+	switch {
+	case t == timeType:
+		return true
+	case t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// the encoding is only known at runtime
+		return false
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		// encoded as a string
+		return true
+	}
+
+	switch t.Kind() {
+	case reflect.Interface, reflect.Map:
+		return false
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return computeStaticMemberNames(t.Elem(), mode, visiting)
+	case reflect.Struct:
+		if visiting[t] {
+			// recursive types are validated at runtime
+			return false
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		for _, f := range cachedJSONFields(t) {
+			if !isValidMemberName(f.name, mode) || !computeStaticMemberNames(f.typ, mode, visiting) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// validateValueMemberNames verifies the member names found in the JSON encoding of v, located at
+// path, without encoding it whenever possible.
+func validateValueMemberNames(v reflect.Value, mode MemberNameValidationMode, path string) error {
+	if mode == DisableValidation || !v.IsValid() || hasStaticMemberNames(v.Type(), mode) {
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+	}
+
+	// Optional and Nullable are validated through the value they wrap rather than encoded
+	if _, ok := wrappedType(v.Type()); ok {
+		wv := reflect.Indirect(v)
+		if wv.CanInterface() {
+			value, ok := wv.Interface().(wrappedAttribute).wrapped()
+			if !ok {
+				return nil
+			}
+			return validateValueMemberNames(reflect.ValueOf(value), mode, path)
+		}
+	}
+
+	// custom marshalers are encoded to find out which member names they produce
+	var marshaler json.Marshaler
+	if v.Type().Implements(jsonMarshalerType) && v.CanInterface() {
+		marshaler, _ = v.Interface().(json.Marshaler)
+	} else if v.CanAddr() && reflect.PointerTo(v.Type()).Implements(jsonMarshalerType) && v.Addr().CanInterface() {
+		marshaler, _ = v.Addr().Interface().(json.Marshaler)
+	}
+	if marshaler != nil {
+		b, err := json.Marshal(marshaler)
+		if err != nil {
+			return err
+		}
+		return validateJSONMemberNames(b, mode, path)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return validateValueMemberNames(v.Elem(), mode, path)
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			name, err := mapKeyName(iter.Key())
+			if err != nil {
+				return err
+			}
+			if err := checkMemberName(name, path, mode); err != nil {
+				return err
+			}
+			if err := validateValueMemberNames(iter.Value(), mode, memberPath(path, name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := validateValueMemberNames(v.Index(i), mode, indexPath(path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for _, f := range cachedJSONFields(v.Type()) {
+			fv, ok := schema.FieldByIndex(v, f.index, false)
+			if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+				continue
+			}
+			if err := checkMemberName(f.name, path, mode); err != nil {
+				return err
+			}
+			if err := validateValueMemberNames(fv, mode, memberPath(path, f.name)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// wrappedType returns the type of the value wrapped by t, an Optional or Nullable or a pointer to
+// one, and false for any other type.
+func wrappedType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || !t.Implements(wrappedAttrType) {
+		return nil, false
+	}
+	return reflect.Zero(t).Interface().(wrappedAttribute).wrappedType(), true
+}
+
+// mapKeyName returns the member name encoding/json uses for the given map key.
+func mapKeyName(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := k.Interface().(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+
+	return "", &TypeError{Actual: k.Type().String(), Expected: []string{"string", "int", "encoding.TextMarshaler"}}
+}
+
+// isEmptyValue reports whether v is empty as defined by the omitempty option of encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
 }
@@ -1,7 +1,10 @@
 package jsonapi
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DataDog/jsonapi/internal/is"
 )
@@ -62,3 +65,130 @@ func TestIsValidMemberName(t *testing.T) {
 		}
 	}
 }
+
+type customMarshaler struct{}
+
+func (customMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{"in%valid":1}`), nil
+}
+
+func TestValidateValueMemberNames(t *testing.T) {
+	t.Parallel()
+
+	type nested struct {
+		Valid   string         `json:"valid"`
+		Invalid string         `json:"in%valid,omitempty"`
+		Map     map[string]any `json:"map,omitempty"`
+	}
+
+	tests := []struct {
+		description string
+		given       any
+		mode        MemberNameValidationMode
+		expectError error
+	}{
+		{
+			description: "nil",
+			given:       nil,
+		}, {
+			description: "string",
+			given:       "in%valid",
+		}, {
+			description: "time.Time",
+			given:       time.Now(),
+		}, {
+			description: "struct with omitted invalid member name",
+			given:       nested{Valid: "a"},
+		}, {
+			description: "struct with invalid member name",
+			given:       nested{Invalid: "a"},
+			expectError: &MemberNameValidationError{MemberName: "in%valid", Path: "/x/in%valid"},
+		}, {
+			description: "struct with invalid member name (DisableValidation)",
+			given:       nested{Invalid: "a"},
+			mode:        DisableValidation,
+		}, {
+			description: "struct with invalid nested map member name",
+			given:       &nested{Map: map[string]any{"list": []any{map[string]any{"a/b~": 1}}}},
+			expectError: &MemberNameValidationError{MemberName: "a/b~", Path: "/x/map/list/0/a~1b~0"},
+		}, {
+			description: "struct with invalid member name (StrictValidation)",
+			given:       nested{Map: map[string]any{"snake_case": 1}},
+			mode:        StrictValidation,
+			expectError: &MemberNameValidationError{MemberName: "snake_case", Path: "/x/map/snake_case"},
+		}, {
+			description: "json.Marshaler",
+			given:       []customMarshaler{{}},
+			expectError: &MemberNameValidationError{MemberName: "in%valid", Path: "/x/0/in%valid"},
+		}, {
+			description: "Optional with invalid member name",
+			given:       NewOptional(map[string]any{"in%valid": 1}),
+			expectError: &MemberNameValidationError{MemberName: "in%valid", Path: "/x/in%valid"},
+		}, {
+			description: "*Nullable of json.Marshaler",
+			given:       &Nullable[customMarshaler]{value: customMarshaler{}, state: nullableSet},
+			expectError: &MemberNameValidationError{MemberName: "in%valid", Path: "/x/in%valid"},
+		}, {
+			description: "null Nullable of json.Marshaler",
+			given:       Null[customMarshaler](),
+		}, {
+			description: "map with int keys",
+			given:       map[int]string{1: "a"},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			err := validateValueMemberNames(reflect.ValueOf(tc.given), tc.mode, "/x")
+			is.EqualError(t, tc.expectError, err)
+		})
+	}
+}
+
+func TestHasStaticMemberNames(t *testing.T) {
+	t.Parallel()
+
+	type recursive struct {
+		Children []recursive `json:"children"`
+	}
+
+	tests := []struct {
+		description string
+		given       any
+		expect      bool
+	}{
+		{description: "string", given: "", expect: true},
+		{description: "time.Time", given: time.Time{}, expect: true},
+		{description: "*ArticleInfo", given: &ArticleInfo{}, expect: true},
+		{description: "Article", given: Article{}, expect: true},
+		{description: "map[string]any", given: map[string]any{}, expect: false},
+		{description: "Link", given: Link{}, expect: false},
+		{description: "customMarshaler", given: customMarshaler{}, expect: false},
+		{description: "recursive", given: recursive{}, expect: false},
+		{description: "Optional[string]", given: Optional[string]{}, expect: true},
+		{description: "*Nullable[ArticleInfo]", given: &Nullable[ArticleInfo]{}, expect: true},
+		{description: "Optional[map[string]any]", given: Optional[map[string]any]{}, expect: false},
+		{description: "Nullable[customMarshaler]", given: Nullable[customMarshaler]{}, expect: false},
+		{
+			description: "invalid member name",
+			given: struct {
+				Invalid string `json:"in%valid"`
+			}{},
+			expect: false,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			is.Equal(t, tc.expect, hasStaticMemberNames(reflect.TypeOf(tc.given), DefaultValidation))
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// UnmarshalMerge unmarshals a document onto the values the target already holds, e.g. to apply the
+// document of a PATCH request to a model loaded from storage, as described by
+// https://jsonapi.org/format/1.0/#crud-updating:
+//
+//   - attributes and relationships missing from a resource object are left untouched
+//   - attributes which are present replace the field values, instead of being merged into them
+//     like encoding/json does with maps and structs, so that null clears them
+//   - a to-one relationship whose data is null clears the field, and one linking the resource the
+//     field already holds updates that value in place rather than replacing it
+//   - a to-many relationship is replaced by exactly the resources of its linkage, reusing the
+//     values of the ones the field already holds
+//
+// Primary data unmarshaled into a slice is merged like a to-many relationship. Resources are matched
+// by type and id, so values without an id are never merged into.
+func UnmarshalMerge() UnmarshalOption {
+	return func(m *Unmarshaler) {
+		m.merge = true
+	}
+}
+
+// existingIdentifier returns the identifier of the resource held by rv, which may be a struct or a
+// pointer or interface holding one, if it has a non-empty id.
+func existingIdentifier(rv reflect.Value) (string, bool) {
+	for rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return "", false
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return "", false
+	}
+
+	fields, err := schema.CachedTypeFields(rv.Type())
+	if err != nil {
+		return "", false
+	}
+	for _, f := range fields {
+		if f.Tag.Directive != schema.Primary {
+			continue
+		}
+		fv, ok := schema.FieldByIndex(rv, f.Index, false)
+		if !ok {
+			return "", false
+		}
+
+		// MarshalIdentifier may have a pointer receiver
+		v := rv.Interface()
+		if rv.CanAddr() {
+			v = rv.Addr().Interface()
+		}
+		id, err := schema.MarshalPrimary(v, fv.Interface())
+		if err != nil || id == "" {
+			return "", false
+		}
+		ro := resourceObject{Type: f.Tag.ResourceType, ID: id}
+		return ro.getIdentifier(), true
+	}
+
+	return "", false
+}
+
+// existingResources returns the elements of the slice sv by resource identifier.
+func existingResources(sv reflect.Value) map[string]reflect.Value {
+	resources := make(map[string]reflect.Value, sv.Len())
+	for i := 0; i < sv.Len(); i++ {
+		if rid, ok := existingIdentifier(sv.Index(i)); ok {
+			resources[rid] = sv.Index(i)
+		}
+	}
+	return resources
+}
+
+// mergeTarget returns a pointer to unmarshal a resource object into in place of a new value of type
+// t, which holds the existing value ev of type t. Only structs and pointers to structs are merged.
+func mergeTarget(ev reflect.Value, t reflect.Type) (any, bool) {
+	switch {
+	case t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct:
+		return ev.Interface(), true
+	case t.Kind() == reflect.Struct:
+		p := reflect.New(t)
+		p.Elem().Set(ev)
+		return p.Interface(), true
+	default:
+		return nil, false
+	}
+}
+
+// resetAttributes zeroes the attribute fields of rv, a struct, which are present in the raw
+// attributes b, so that they are replaced rather than merged into.
+func resetAttributes(b []byte, rv reflect.Value) error {
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(b, &present); err != nil {
+		return err
+	}
+
+	fields, err := schema.CachedTypeFields(rv.Type())
+	if err != nil {
+		return err
+	}
+	for i := range fields {
+		ft := &fields[i]
+		if ft.Tag.Directive != schema.Attribute || !ft.Exported {
+			continue
+		}
+		if _, ok := present[ft.MemberName]; !ok {
+			continue
+		}
+		if fv, ok := schema.FieldByIndex(rv, ft.Index, false); ok {
+			fv.Set(reflect.Zero(fv.Type()))
+		}
+	}
+
+	return nil
+}
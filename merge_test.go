@@ -0,0 +1,101 @@
+package jsonapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestUnmarshalMerge(t *testing.T) {
+	t.Parallel()
+
+	// loaded returns an article as loaded from storage, before a document is merged onto it
+	loaded := func() *ArticleRelated {
+		return &ArticleRelated{
+			ID:     "1",
+			Title:  "A",
+			Author: &Author{ID: "1", Name: "A"},
+			Comments: []*Comment{
+				{ID: "1", Body: "A"},
+				{ID: "2", Body: "B", Author: &Author{ID: "1", Name: "A"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		description string
+		given       string
+		expect      func(a *ArticleRelated)
+	}{
+		{
+			description: "absent members are untouched",
+			given:       `{"data":{"id":"1","type":"articles"}}`,
+			expect:      func(a *ArticleRelated) {},
+		}, {
+			description: "attributes",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"title":"B"}}}`,
+			expect:      func(a *ArticleRelated) { a.Title = "B" },
+		}, {
+			description: "null attribute",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"title":null}}}`,
+			expect:      func(a *ArticleRelated) { a.Title = "" },
+		}, {
+			description: "null to-one relationship",
+			given:       `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":null}}}}`,
+			expect:      func(a *ArticleRelated) { a.Author = nil },
+		}, {
+			description: "to-one relationship to the same resource",
+			given:       `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"1","type":"author"}}}}}`,
+			expect:      func(a *ArticleRelated) {},
+		}, {
+			description: "to-one relationship to the same included resource",
+			given:       `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"1","type":"author"}}}},"included":[{"id":"1","type":"author","attributes":{"name":"B"}}]}`,
+			expect:      func(a *ArticleRelated) { a.Author.Name = "B" },
+		}, {
+			description: "to-one relationship to another resource",
+			given:       `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"2","type":"author"}}}}}`,
+			expect:      func(a *ArticleRelated) { a.Author = &Author{ID: "2"} },
+		}, {
+			description: "to-many relationship",
+			given:       `{"data":{"id":"1","type":"articles","relationships":{"comments":{"data":[{"id":"2","type":"comments"},{"id":"3","type":"comments"}]}}}}`,
+			expect:      func(a *ArticleRelated) { a.Comments = []*Comment{a.Comments[1], {ID: "3"}} },
+		}, {
+			description: "empty to-many relationship",
+			given:       `{"data":{"id":"1","type":"articles","relationships":{"comments":{"data":[]}}}}`,
+			expect:      func(a *ArticleRelated) { a.Comments = []*Comment{} },
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			expect := loaded()
+			tc.expect(expect)
+
+			a := loaded()
+			author := a.Author
+			err := Unmarshal([]byte(tc.given), a, UnmarshalMerge())
+			is.MustNoError(t, err)
+			is.Equal(t, expect, a)
+			if a.Author != nil && a.Author.ID == author.ID {
+				is.Equal(t, true, a.Author == author)
+			}
+		})
+	}
+}
+
+func TestUnmarshalMergeSlice(t *testing.T) {
+	t.Parallel()
+
+	a, b := &Article{ID: "1", Title: "A"}, &Article{ID: "2", Title: "B"}
+	articles := []*Article{a, b}
+
+	body := `{"data":[{"id":"2","type":"articles","attributes":{"title":"C"}},{"id":"3","type":"articles"}]}`
+	is.MustNoError(t, Unmarshal([]byte(body), &articles, UnmarshalMerge()))
+	is.Equal(t, []*Article{{ID: "2", Title: "C"}, {ID: "3"}}, articles)
+	is.Equal(t, true, articles[0] == b)
+}
@@ -0,0 +1,194 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// absentAttribute is implemented by attribute types which may be left out of a resource object.
+type absentAttribute interface {
+	isAbsent() bool
+}
+
+// isAbsent returns true if the attribute value v must be left out of the resource object. A nil
+// pointer to an Optional or Nullable isn't absent, and is marshaled as null.
+func isAbsent(v any) bool {
+	a, ok := v.(absentAttribute)
+	if !ok {
+		return false
+	}
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return false
+	}
+	return a.isAbsent()
+}
+
+// wrappedAttribute is implemented by attribute types encoded as the value they wrap, or null, so
+// that their member names are the ones of the wrapped value.
+type wrappedAttribute interface {
+	// wrapped returns the wrapped value, and false if it is encoded as null instead.
+	wrapped() (any, bool)
+	// wrappedType returns the type of the wrapped value.
+	wrappedType() reflect.Type
+}
+
+// Optional is an attribute which may be absent from a resource object, as opposed to being set to
+// a value. When unmarshaling, it records whether the attribute was present, which a zero value
+// can't tell (e.g. whether a PATCH request leaves an attribute unchanged). When marshaling, an
+// absent attribute is left out of the resource object regardless of omitempty.
+//
+// A JSON null is unmarshaled like any other value of T. See Nullable to tell it apart.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// NewOptional returns an Optional set to the given value.
+func NewOptional[T any](v T) Optional[T] {
+	return Optional[T]{value: v, set: true}
+}
+
+// Get returns the value of o and whether it is set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// IsSet returns true if o is set to a value, i.e. the attribute is present.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// Set sets o to the given value.
+func (o *Optional[T]) Set(v T) {
+	o.value = v
+	o.set = true
+}
+
+// Unset makes o absent.
+func (o *Optional[T]) Unset() {
+	*o = Optional[T]{}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	o.Set(v)
+	return nil
+}
+
+func (o Optional[T]) isAbsent() bool {
+	return !o.set
+}
+
+func (o Optional[T]) wrapped() (any, bool) {
+	return o.value, o.set
+}
+
+func (Optional[T]) wrappedType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// nullableState is the state of a Nullable.
+type nullableState uint8
+
+const (
+	nullableAbsent nullableState = iota
+	nullableNull
+	nullableSet
+)
+
+// Nullable is an attribute which may be absent from a resource object, null, or set to a value,
+// as needed by PATCH requests where an absent attribute is left unchanged while null clears it.
+// Unmarshaling records which of the three was received, and marshaling writes it back: an absent
+// attribute is left out of the resource object regardless of omitempty, and a null one is null.
+type Nullable[T any] struct {
+	value T
+	state nullableState
+}
+
+// NewNullable returns a Nullable set to the given value.
+func NewNullable[T any](v T) Nullable[T] {
+	return Nullable[T]{value: v, state: nullableSet}
+}
+
+// Null returns a null Nullable.
+func Null[T any]() Nullable[T] {
+	return Nullable[T]{state: nullableNull}
+}
+
+// Get returns the value of n and whether it is set to a value, i.e. neither absent nor null.
+func (n Nullable[T]) Get() (T, bool) {
+	return n.value, n.state == nullableSet
+}
+
+// IsSet returns true if n is null or set to a value, i.e. the attribute is present.
+func (n Nullable[T]) IsSet() bool {
+	return n.state != nullableAbsent
+}
+
+// IsNull returns true if n is null.
+func (n Nullable[T]) IsNull() bool {
+	return n.state == nullableNull
+}
+
+// Set sets n to the given value.
+func (n *Nullable[T]) Set(v T) {
+	n.value = v
+	n.state = nullableSet
+}
+
+// SetNull makes n null.
+func (n *Nullable[T]) SetNull() {
+	*n = Null[T]()
+}
+
+// Unset makes n absent.
+func (n *Nullable[T]) Unset() {
+	*n = Nullable[T]{}
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (n Nullable[T]) MarshalJSON() ([]byte, error) {
+	if n.state != nullableSet {
+		return []byte("null"), nil
+	}
+	return json.Marshal(n.value)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		n.SetNull()
+		return nil
+	}
+
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	n.Set(v)
+	return nil
+}
+
+func (n Nullable[T]) isAbsent() bool {
+	return n.state == nullableAbsent
+}
+
+func (n Nullable[T]) wrapped() (any, bool) {
+	return n.value, n.state == nullableSet
+}
+
+func (Nullable[T]) wrappedType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
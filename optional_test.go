@@ -0,0 +1,98 @@
+package jsonapi
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestOptionalAttributes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		expect      ArticlePatch
+	}{
+		{
+			description: "absent",
+			given:       `{"data":{"id":"1","type":"articles"}}`,
+			expect:      ArticlePatch{ID: "1"},
+		}, {
+			description: "null",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"subtitle":null,"tags":null}}}`,
+			expect:      ArticlePatch{ID: "1", Subtitle: Null[string](), Tags: Null[[]string]()},
+		}, {
+			description: "set",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"title":"A","subtitle":"B","tags":[]}}}`,
+			expect: ArticlePatch{
+				ID:       "1",
+				Title:    NewOptional("A"),
+				Subtitle: NewNullable("B"),
+				Tags:     NewNullable([]string{}),
+			},
+		}, {
+			description: "set to zero values",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"title":"","subtitle":""}}}`,
+			expect:      ArticlePatch{ID: "1", Title: NewOptional(""), Subtitle: NewNullable("")},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			var a ArticlePatch
+			err := Unmarshal([]byte(tc.given), &a)
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, a)
+
+			b, err := Marshal(&tc.expect)
+			is.MustNoError(t, err)
+			is.EqualJSON(t, tc.given, string(b))
+		})
+	}
+}
+
+func TestNullable(t *testing.T) {
+	t.Parallel()
+
+	var n Nullable[int]
+	is.Equal(t, false, n.IsSet())
+
+	n.SetNull()
+	is.Equal(t, true, n.IsSet())
+	is.Equal(t, true, n.IsNull())
+
+	n.Set(1)
+	v, ok := n.Get()
+	is.Equal(t, 1, v)
+	is.Equal(t, true, ok)
+	is.Equal(t, false, n.IsNull())
+
+	n.Unset()
+	is.Equal(t, Nullable[int]{}, n)
+}
+
+func TestOptionalNilPointers(t *testing.T) {
+	t.Parallel()
+
+	expect := `{"data":{"id":"1","type":"articles","attributes":{"title":null,"subtitle":null}}}`
+
+	b, err := Marshal(&ArticlePatchPointers{ID: "1"})
+	is.MustNoError(t, err)
+	is.EqualJSON(t, expect, string(b))
+
+	b, err = Marshal(&ArticlePatchBuilder{ID: "1"})
+	is.MustNoError(t, err)
+	is.EqualJSON(t, expect, string(b))
+
+	// pointers to absent values are still left out
+	title, subtitle := Optional[string]{}, Nullable[string]{}
+	b, err = Marshal(&ArticlePatchBuilder{ID: "1", Title: &title, Subtitle: &subtitle})
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":{"id":"1","type":"articles"}}`, string(b))
+}
@@ -0,0 +1,272 @@
+package jsonapi
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Paginator is a pagination strategy as described by https://jsonapi.org/format/1.0/#fetching-pagination.
+// PageNumber, OffsetLimit and Cursor implement it, and are created from the page[NAME] query
+// parameters (see Query.Page) by ParsePageNumber, ParseOffsetLimit and ParseCursor.
+type Paginator interface {
+	// Links returns the links of the current page of the collection at the request URL u, for
+	// MarshalLinks. The query parameters of u other than page[NAME] are preserved.
+	Links(u *url.URL) *Link
+
+	// Meta returns the pagination meta of the current page (e.g. the total number of pages), for
+	// MarshalMeta, or nil.
+	Meta() map[string]any
+}
+
+// PageNumber is the page-based pagination strategy, using the page[number] and page[size] query
+// parameters. Page numbers start at 1.
+type PageNumber struct {
+	Number int
+	Size   int
+
+	// Total is the number of resources in the collection, which must be set before calling Links.
+	Total int
+}
+
+// ParsePageNumber returns the PageNumber of the given page[NAME] query parameters. The page size
+// defaults to defaultSize, which must be at least 1, and at most maxSize if it is positive.
+//
+// Invalid parameters, and page parameters other than page[number] and page[size], are errors like
+// the ones of ParseQuery.
+func ParsePageNumber(page map[string]string, defaultSize, maxSize int) (*PageNumber, error) {
+	if err := checkPageParameters(page, "number", "size"); err != nil {
+		return nil, err
+	}
+
+	number, err := parsePageParameter(page, "number", 1, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	size, err := parsePageParameter(page, "size", defaultSize, 1, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PageNumber{Number: number, Size: size}, nil
+}
+
+// pages returns the number of pages of p, which is at least 1. Without a page size, all the
+// resources are on a single page.
+func (p *PageNumber) pages() int {
+	if p.Size < 1 || p.Total <= p.Size {
+		return 1
+	}
+	return (p.Total + p.Size - 1) / p.Size
+}
+
+// Links implements the Paginator interface.
+func (p *PageNumber) Links(u *url.URL) *Link {
+	page := func(number int) string {
+		return pageURL(u, map[string]string{"number": strconv.Itoa(number), "size": strconv.Itoa(p.Size)})
+	}
+
+	last := p.pages()
+	l := &Link{Self: page(p.Number), First: page(1), Last: page(last)}
+	if p.Number > 1 {
+		// pages past the last one link back to it
+		prev := p.Number - 1
+		if prev > last {
+			prev = last
+		}
+		l.Prev = page(prev)
+	}
+	if p.Number < last {
+		l.Next = page(p.Number + 1)
+	}
+	return l
+}
+
+// Meta implements the Paginator interface.
+func (p *PageNumber) Meta() map[string]any {
+	return map[string]any{"total": p.Total, "totalPages": p.pages()}
+}
+
+// OffsetLimit is the offset-based pagination strategy, using the page[offset] and page[limit] query
+// parameters. Offsets start at 0.
+type OffsetLimit struct {
+	Offset int
+	Limit  int
+
+	// Total is the number of resources in the collection, which must be set before calling Links.
+	Total int
+}
+
+// ParseOffsetLimit returns the OffsetLimit of the given page[NAME] query parameters. The limit
+// defaults to defaultLimit, which must be at least 1, and at most maxLimit if it is positive.
+//
+// Invalid parameters, and page parameters other than page[offset] and page[limit], are errors like
+// the ones of ParseQuery.
+func ParseOffsetLimit(page map[string]string, defaultLimit, maxLimit int) (*OffsetLimit, error) {
+	if err := checkPageParameters(page, "offset", "limit"); err != nil {
+		return nil, err
+	}
+
+	offset, err := parsePageParameter(page, "offset", 0, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := parsePageParameter(page, "limit", defaultLimit, 1, maxLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OffsetLimit{Offset: offset, Limit: limit}, nil
+}
+
+// Links implements the Paginator interface.
+func (p *OffsetLimit) Links(u *url.URL) *Link {
+	page := func(offset int) string {
+		return pageURL(u, map[string]string{"offset": strconv.Itoa(offset), "limit": strconv.Itoa(p.Limit)})
+	}
+
+	// without a limit, all the resources are on a single page
+	last := 0
+	if p.Total > 0 && p.Limit > 0 {
+		last = (p.Total - 1) / p.Limit * p.Limit
+	}
+	l := &Link{Self: page(p.Offset), First: page(0), Last: page(last)}
+	if p.Offset > 0 {
+		// offsets past the last page link back to it
+		prev := p.Offset - p.Limit
+		if prev > last {
+			prev = last
+		}
+		if prev < 0 {
+			prev = 0
+		}
+		l.Prev = page(prev)
+	}
+	if p.Limit > 0 && p.Offset+p.Limit < p.Total {
+		l.Next = page(p.Offset + p.Limit)
+	}
+	return l
+}
+
+// Meta implements the Paginator interface.
+func (p *OffsetLimit) Meta() map[string]any {
+	return map[string]any{"total": p.Total}
+}
+
+// Cursor is the cursor-based pagination strategy, using the page[cursor] and page[size] query
+// parameters. The first page has no cursor.
+type Cursor struct {
+	Cursor string
+	Size   int
+
+	// Next is the cursor of the next page, which must be set before calling Links unless the
+	// current page is the last one.
+	Next string
+}
+
+// ParseCursor returns the Cursor of the given page[NAME] query parameters. The page size defaults
+// to defaultSize, which must be at least 1, and at most maxSize if it is positive.
+//
+// Invalid parameters, and page parameters other than page[cursor] and page[size], are errors like
+// the ones of ParseQuery.
+func ParseCursor(page map[string]string, defaultSize, maxSize int) (*Cursor, error) {
+	if err := checkPageParameters(page, "cursor", "size"); err != nil {
+		return nil, err
+	}
+
+	size, err := parsePageParameter(page, "size", defaultSize, 1, maxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cursor{Cursor: page["cursor"], Size: size}, nil
+}
+
+// Links implements the Paginator interface.
+func (p *Cursor) Links(u *url.URL) *Link {
+	page := func(cursor string) string {
+		params := map[string]string{"size": strconv.Itoa(p.Size)}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		return pageURL(u, params)
+	}
+
+	l := &Link{Self: page(p.Cursor), First: page("")}
+	if p.Next != "" {
+		l.Next = page(p.Next)
+	}
+	return l
+}
+
+// Meta implements the Paginator interface.
+func (p *Cursor) Meta() map[string]any {
+	return nil
+}
+
+// checkPageParameters returns an error if page has parameters other than the given names.
+func checkPageParameters(page map[string]string, names ...string) error {
+	unknown := make([]string, 0)
+	for name := range page {
+		if !containsString(names, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	parameter := "page[" + unknown[0] + "]"
+	return queryError(parameter, "%q is not a supported query parameter", parameter)
+}
+
+// parsePageParameter returns the integer value of the page parameter with the given name, or def
+// if there is none. The value and the default must be at least lo, and at most hi if it is positive.
+func parsePageParameter(page map[string]string, name string, def, lo, hi int) (int, error) {
+	if def < lo || (hi > 0 && def > hi) {
+		return 0, ErrInvalidPageSize
+	}
+
+	value, ok := page[name]
+	if !ok {
+		return def, nil
+	}
+
+	parameter := "page[" + name + "]"
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo {
+		return 0, queryError(parameter, "%q must be an integer of at least %d", parameter, lo)
+	}
+	if hi > 0 && n > hi {
+		return 0, queryError(parameter, "%q must be at most %d", parameter, hi)
+	}
+	return n, nil
+}
+
+// pageURL returns u with its page[NAME] query parameters replaced by the given ones.
+func pageURL(u *url.URL, page map[string]string) string {
+	query := u.Query()
+	for name := range query {
+		if strings.HasPrefix(name, "page[") {
+			query.Del(name)
+		}
+	}
+	for name, value := range page {
+		query.Set("page["+name+"]", value)
+	}
+
+	pu := *u
+	pu.RawQuery = query.Encode()
+	return pu.String()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
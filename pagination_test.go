@@ -0,0 +1,224 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestParsePaginator(t *testing.T) {
+	t.Parallel()
+
+	pageNumber := func(page map[string]string) (Paginator, error) { return ParsePageNumber(page, 10, 100) }
+	offsetLimit := func(page map[string]string) (Paginator, error) { return ParseOffsetLimit(page, 10, 100) }
+	cursor := func(page map[string]string) (Paginator, error) { return ParseCursor(page, 10, 100) }
+
+	tests := []struct {
+		description string
+		parse       func(map[string]string) (Paginator, error)
+		given       map[string]string
+		expect      Paginator
+		expectError error
+	}{
+		{
+			description: "page number defaults",
+			parse:       pageNumber,
+			given:       nil,
+			expect:      &PageNumber{Number: 1, Size: 10},
+		}, {
+			description: "page number",
+			parse:       pageNumber,
+			given:       map[string]string{"number": "3", "size": "20"},
+			expect:      &PageNumber{Number: 3, Size: 20},
+		}, {
+			description: "page number of zero",
+			parse:       pageNumber,
+			given:       map[string]string{"number": "0"},
+			expectError: queryError("page[number]", `"page[number]" must be an integer of at least 1`),
+		}, {
+			description: "page size too large",
+			parse:       pageNumber,
+			given:       map[string]string{"size": "101"},
+			expectError: queryError("page[size]", `"page[size]" must be at most 100`),
+		}, {
+			description: "page size not an integer",
+			parse:       pageNumber,
+			given:       map[string]string{"size": "ten"},
+			expectError: queryError("page[size]", `"page[size]" must be an integer of at least 1`),
+		}, {
+			description: "unsupported page parameter",
+			parse:       pageNumber,
+			given:       map[string]string{"offset": "10", "limit": "10"},
+			expectError: queryError("page[limit]", `"page[limit]" is not a supported query parameter`),
+		}, {
+			description: "offset limit defaults",
+			parse:       offsetLimit,
+			given:       nil,
+			expect:      &OffsetLimit{Offset: 0, Limit: 10},
+		}, {
+			description: "offset limit",
+			parse:       offsetLimit,
+			given:       map[string]string{"offset": "30", "limit": "15"},
+			expect:      &OffsetLimit{Offset: 30, Limit: 15},
+		}, {
+			description: "negative offset",
+			parse:       offsetLimit,
+			given:       map[string]string{"offset": "-1"},
+			expectError: queryError("page[offset]", `"page[offset]" must be an integer of at least 0`),
+		}, {
+			description: "cursor",
+			parse:       cursor,
+			given:       map[string]string{"cursor": "abc", "size": "5"},
+			expect:      &Cursor{Cursor: "abc", Size: 5},
+		}, {
+			description: "page number without default size",
+			parse:       func(page map[string]string) (Paginator, error) { return ParsePageNumber(page, 0, 0) },
+			given:       nil,
+			expectError: ErrInvalidPageSize,
+		}, {
+			description: "offset limit without default limit",
+			parse:       func(page map[string]string) (Paginator, error) { return ParseOffsetLimit(page, 0, 0) },
+			given:       map[string]string{"limit": "5"},
+			expectError: ErrInvalidPageSize,
+		}, {
+			description: "page number with default size above the maximum",
+			parse:       func(page map[string]string) (Paginator, error) { return ParsePageNumber(page, 100, 50) },
+			given:       nil,
+			expectError: ErrInvalidPageSize,
+		}, {
+			description: "offset limit with default limit above the maximum",
+			parse:       func(page map[string]string) (Paginator, error) { return ParseOffsetLimit(page, 100, 50) },
+			given:       map[string]string{"limit": "5"},
+			expectError: ErrInvalidPageSize,
+		}, {
+			description: "cursor with default size above the maximum",
+			parse:       func(page map[string]string) (Paginator, error) { return ParseCursor(page, 100, 50) },
+			given:       nil,
+			expectError: ErrInvalidPageSize,
+		}, {
+			description: "cursor with page number",
+			parse:       cursor,
+			given:       map[string]string{"number": "2"},
+			expectError: queryError("page[number]", `"page[number]" is not a supported query parameter`),
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := tc.parse(tc.given)
+			if tc.expectError != nil {
+				is.Equal(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+func TestPaginatorLinks(t *testing.T) {
+	t.Parallel()
+
+	u, err := url.Parse("https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=2")
+	is.MustNoError(t, err)
+
+	tests := []struct {
+		description string
+		given       Paginator
+		expectLinks *Link
+		expectMeta  map[string]any
+	}{
+		{
+			description: "first page number",
+			given:       &PageNumber{Number: 1, Size: 10, Total: 25},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				Last:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=3&page%5Bsize%5D=10",
+				Next:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=2&page%5Bsize%5D=10",
+			},
+			expectMeta: map[string]any{"total": 25, "totalPages": 3},
+		}, {
+			description: "page number past the last page",
+			given:       &PageNumber{Number: 5, Size: 10, Total: 25},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=5&page%5Bsize%5D=10",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				Last:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=3&page%5Bsize%5D=10",
+				Prev:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=3&page%5Bsize%5D=10",
+			},
+			expectMeta: map[string]any{"total": 25, "totalPages": 3},
+		}, {
+			description: "empty collection by page number",
+			given:       &PageNumber{Number: 1, Size: 10},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=10",
+				Last:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=10",
+			},
+			expectMeta: map[string]any{"total": 0, "totalPages": 1},
+		}, {
+			description: "middle offset",
+			given:       &OffsetLimit{Offset: 5, Limit: 10, Total: 25},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=10&page%5Boffset%5D=5",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=10&page%5Boffset%5D=0",
+				Last:  "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=10&page%5Boffset%5D=20",
+				Prev:  "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=10&page%5Boffset%5D=0",
+				Next:  "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=10&page%5Boffset%5D=15",
+			},
+			expectMeta: map[string]any{"total": 25},
+		}, {
+			description: "offset without limit",
+			given:       &OffsetLimit{Total: 5},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=0&page%5Boffset%5D=0",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=0&page%5Boffset%5D=0",
+				Last:  "https://example.com/articles?filter%5Btag%5D=go&page%5Blimit%5D=0&page%5Boffset%5D=0",
+			},
+			expectMeta: map[string]any{"total": 5},
+		}, {
+			description: "page number without size",
+			given:       &PageNumber{Total: 5},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=0&page%5Bsize%5D=0",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=0",
+				Last:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=0",
+				Next:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bnumber%5D=1&page%5Bsize%5D=0",
+			},
+			expectMeta: map[string]any{"total": 5, "totalPages": 1},
+		}, {
+			description: "cursor with next page",
+			given:       &Cursor{Cursor: "abc", Size: 10, Next: "def"},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bcursor%5D=abc&page%5Bsize%5D=10",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Bsize%5D=10",
+				Next:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bcursor%5D=def&page%5Bsize%5D=10",
+			},
+		}, {
+			description: "last cursor page",
+			given:       &Cursor{Cursor: "def", Size: 10},
+			expectLinks: &Link{
+				Self:  "https://example.com/articles?filter%5Btag%5D=go&page%5Bcursor%5D=def&page%5Bsize%5D=10",
+				First: "https://example.com/articles?filter%5Btag%5D=go&page%5Bsize%5D=10",
+			},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			is.Equal(t, tc.expectLinks, tc.given.Links(u))
+			is.Equal(t, tc.expectMeta, tc.given.Meta())
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// familyParameterRegex matches the names of query parameter families like page[size] or
+// filter[author][name], capturing the base name and the bracketed names.
+var familyParameterRegex = regexp.MustCompile(`^([^\[\]]+)((?:\[[^\[\]]+\])+)$`)
+
+// Query holds the query parameters of a request, as defined by
+// https://jsonapi.org/format/1.0/#fetching and https://jsonapi.org/format/1.0/#query-parameters.
+type Query struct {
+	// Include holds the relationship paths of the include parameter (e.g. "comments.author" is
+	// []string{"comments", "author"}). See MarshalIncludePaths.
+	Include [][]string
+
+	// Fields holds the sparse fieldsets of the fields[TYPE] parameters by resource type. See
+	// MarshalFields.
+	Fields map[string][]string
+
+	// Sort holds the sort fields of the sort parameter, in order.
+	Sort Sort
+
+	// Page holds the page[NAME] parameters by name (e.g. "size" for page[size]).
+	Page map[string]string
+
+	// Filter holds the filter and filter[...] parameters, sorted by name. See the filter package.
+	Filter []FilterParameter
+
+	// Params holds the implementation-specific parameters allowed by QueryAllowParameters.
+	Params url.Values
+}
+
+// FilterParameter is a filter[...] query parameter.
+type FilterParameter struct {
+	// Keys holds the bracketed names of the parameter, e.g. []string{"age", "gt"} for filter[age][gt],
+	// or nil for filter.
+	Keys []string
+
+	// Value is the value of the parameter.
+	Value string
+}
+
+// QueryOption allows for configuration of ParseQuery.
+type QueryOption func(q *queryParser)
+
+// QueryAllowParameters allows the given implementation-specific query parameters, which are stored
+// in Query.Params instead of being rejected by ParseQuery.
+func QueryAllowParameters(names ...string) QueryOption {
+	return func(q *queryParser) {
+		for _, name := range names {
+			q.allowed[name] = true
+		}
+	}
+}
+
+// queryParser is configured by QueryOption's.
+type queryParser struct {
+	allowed map[string]bool
+}
+
+// ParseQuery parses the json:api query parameters include, fields[TYPE], sort, page[NAME], filter
+// and filter[...] of a request (e.g. r.URL.Query()).
+//
+// Malformed parameters, like fields without a resource type, and any other parameter not allowed by
+// QueryAllowParameters are rejected as required by the specification. The returned error is then an
+// *Error whose Status is 400 (Bad Request) and whose Source.Parameter is the parameter name, which
+// can be marshaled as the response.
+func ParseQuery(query url.Values, opts ...QueryOption) (*Query, error) {
+	qp := &queryParser{allowed: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(qp)
+	}
+
+	// parameters are parsed in order so that the same error is returned for the same query
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	q := new(Query)
+	for _, name := range names {
+		if err := q.parseParameter(name, query[name], qp); err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+// QueryParameterError returns the error of the query parameter with the given name, like the ones
+// of ParseQuery, whose detail explains why the parameter is invalid. Servers can use it to reject
+// the query parameters they don't support.
+func QueryParameterError(name, detail string) *Error {
+	return &Error{
+		Status: Status(400),
+		Title:  "Invalid query parameter",
+		Detail: detail,
+		Source: &ErrorSource{Parameter: name},
+	}
+}
+
+// queryError returns the error of the query parameter with the given name.
+func queryError(name, format string, args ...any) *Error {
+	return QueryParameterError(name, fmt.Sprintf(format, args...))
+}
+
+// parseParameter parses the query parameter with the given name and values into q.
+func (q *Query) parseParameter(name string, values []string, qp *queryParser) error {
+	if qp.allowed[name] {
+		if q.Params == nil {
+			q.Params = make(url.Values)
+		}
+		q.Params[name] = values
+		return nil
+	}
+
+	base, keys, family := parseFamilyParameter(name)
+	if base != "filter" && len(values) > 1 {
+		return queryError(name, "%q must be given once", name)
+	}
+	var value string
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	switch {
+	case name == "include":
+		return q.parseInclude(name, value)
+	case name == "sort":
+		return q.parseSort(value)
+	case base == "fields":
+		// only fields[TYPE] is valid, as parsed by MarshalFields
+		matches := fieldsQueryRegex.FindStringSubmatch(name)
+		if len(matches) < 2 {
+			return queryError(name, "%q must be of the form fields[TYPE]", name)
+		}
+		if !isValidMemberName(matches[1], DefaultValidation) {
+			return queryError(name, "%q is not a valid resource type", matches[1])
+		}
+		if q.Fields == nil {
+			q.Fields = make(map[string][]string)
+		}
+		fields := []string{}
+		if value != "" {
+			fields = strings.Split(value, ",")
+		}
+		q.Fields[matches[1]] = fields
+	case base == "page":
+		if !family || len(keys) != 1 {
+			return queryError(name, "%q must be of the form page[NAME]", name)
+		}
+		if q.Page == nil {
+			q.Page = make(map[string]string)
+		}
+		q.Page[keys[0]] = value
+	case base == "filter":
+		if !family && name != "filter" {
+			return queryError(name, "%q must be of the form filter or filter[NAME]", name)
+		}
+		for _, value := range values {
+			q.Filter = append(q.Filter, FilterParameter{Keys: keys, Value: value})
+		}
+	default:
+		return queryError(name, "%q is not a supported query parameter", name)
+	}
+
+	return nil
+}
+
+// parseFamilyParameter returns the base name of the query parameter with the given name, along with
+// its bracketed names and whether it has any (e.g. "page", []string{"size"} and true for page[size]).
+func parseFamilyParameter(name string) (string, []string, bool) {
+	matches := familyParameterRegex.FindStringSubmatch(name)
+	if matches == nil {
+		// malformed family parameters (e.g. "page" or "page[") have their base name still
+		if i := strings.IndexByte(name, '['); i >= 0 {
+			return name[:i], nil, false
+		}
+		return name, nil, false
+	}
+
+	keys := strings.Split(strings.Trim(matches[2], "[]"), "][")
+	return matches[1], keys, true
+}
+
+// parseInclude parses the value of the include parameter.
+func (q *Query) parseInclude(name, value string) error {
+	for _, path := range splitIncludePaths(value) {
+		for _, relationship := range path {
+			if relationship == "" {
+				return queryError(name, "%q has an empty relationship name", strings.Join(path, "."))
+			}
+		}
+		q.Include = append(q.Include, path)
+	}
+	return nil
+}
+
+// parseSort parses the value of the sort parameter.
+func (q *Query) parseSort(value string) (err error) {
+	q.Sort, err = ParseSort(value)
+	return
+}
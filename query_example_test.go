@@ -0,0 +1,38 @@
+package jsonapi_test
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/DataDog/jsonapi"
+)
+
+func ExampleParseQuery() {
+	query, _ := url.ParseQuery("include=author&fields[articles]=title&sort=-created&page[size]=10")
+
+	q, err := jsonapi.ParseQuery(query)
+	if err != nil {
+		// err is a *jsonapi.Error, which can be marshaled as a 400 response
+		panic(err)
+	}
+
+	fmt.Println(q.Include, q.Fields, q.Sort, q.Page)
+	// Output: [[author]] map[articles:[title]] -created map[size:10]
+}
+
+func ExamplePageNumber() {
+	u, _ := url.Parse("https://example.com/articles?sort=title&page%5Bnumber%5D=2")
+
+	p, err := jsonapi.ParsePageNumber(map[string]string{"number": "2"}, 10, 100)
+	if err != nil {
+		panic(err)
+	}
+	p.Total = 25
+
+	links := p.Links(u)
+	fmt.Println(links.Prev)
+	fmt.Println(links.Next)
+	// Output:
+	// https://example.com/articles?page%5Bnumber%5D=1&page%5Bsize%5D=10&sort=title
+	// https://example.com/articles?page%5Bnumber%5D=3&page%5Bsize%5D=10&sort=title
+}
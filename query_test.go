@@ -0,0 +1,109 @@
+package jsonapi
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestParseQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		opts        []QueryOption
+		expect      *Query
+		expectError *Error
+	}{
+		{
+			description: "empty",
+			given:       "",
+			expect:      &Query{},
+		}, {
+			description: "every parameter",
+			given:       "include=author,comments.author&fields[articles]=title,author&fields[people]=&sort=-created,title&page[number]=2&page[size]=10&filter[tag]=go&filter[age][gt]=3",
+			expect: &Query{
+				Include: [][]string{{"author"}, {"comments", "author"}},
+				Fields:  map[string][]string{"articles": {"title", "author"}, "people": {}},
+				Sort:    []SortField{{Field: "created", Descending: true}, {Field: "title"}},
+				Page:    map[string]string{"number": "2", "size": "10"},
+				Filter:  []FilterParameter{{Keys: []string{"age", "gt"}, Value: "3"}, {Keys: []string{"tag"}, Value: "go"}},
+			},
+		}, {
+			description: "filter expression",
+			given:       "filter=title eq 'go'&filter[tag]=go",
+			expect:      &Query{Filter: []FilterParameter{{Value: "title eq 'go'"}, {Keys: []string{"tag"}, Value: "go"}}},
+		}, {
+			description: "hyphenated type",
+			given:       "fields[blog-posts]=title,sub-title",
+			expect:      &Query{Fields: map[string][]string{"blog-posts": {"title", "sub-title"}}},
+		}, {
+			description: "invalid type",
+			given:       "fields[-posts]=title",
+			expectError: queryError("fields[-posts]", `"-posts" is not a valid resource type`),
+		}, {
+			description: "repeated filter",
+			given:       "filter[tag]=go&filter[tag]=rust",
+			expect:      &Query{Filter: []FilterParameter{{Keys: []string{"tag"}, Value: "go"}, {Keys: []string{"tag"}, Value: "rust"}}},
+		}, {
+			description: "allowed implementation-specific parameter",
+			given:       "api_key=abc&sort=title",
+			opts:        []QueryOption{QueryAllowParameters("api_key")},
+			expect:      &Query{Sort: []SortField{{Field: "title"}}, Params: url.Values{"api_key": {"abc"}}},
+		}, {
+			description: "unknown parameter",
+			given:       "api_key=abc",
+			expectError: queryError("api_key", `"api_key" is not a supported query parameter`),
+		}, {
+			description: "fields without type",
+			given:       "fields=title",
+			expectError: queryError("fields", `"fields" must be of the form fields[TYPE]`),
+		}, {
+			description: "nested fields",
+			given:       "fields[articles][x]=title",
+			expectError: queryError("fields[articles][x]", `"fields[articles][x]" must be of the form fields[TYPE]`),
+		}, {
+			description: "page without name",
+			given:       "page[]=1",
+			expectError: queryError("page[]", `"page[]" must be of the form page[NAME]`),
+		}, {
+			description: "malformed filter",
+			given:       "filter[tag=go",
+			expectError: queryError("filter[tag", `"filter[tag" must be of the form filter or filter[NAME]`),
+		}, {
+			description: "repeated parameter",
+			given:       "sort=title&sort=created",
+			expectError: queryError("sort", `"sort" must be given once`),
+		}, {
+			description: "empty sort field",
+			given:       "sort=title,,created",
+			expectError: queryError("sort", `"title,,created" has an empty sort field`),
+		}, {
+			description: "empty relationship name",
+			given:       "include=comments..author",
+			expectError: queryError("include", `"comments..author" has an empty relationship name`),
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			query, err := url.ParseQuery(tc.given)
+			is.MustNoError(t, err)
+
+			actual, err := ParseQuery(query, tc.opts...)
+			if tc.expectError != nil {
+				is.Equal(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+		})
+	}
+}
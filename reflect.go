@@ -18,14 +18,6 @@ func derefValue(v reflect.Value) reflect.Value {
 	return v
 }
 
-func derefType(t reflect.Type) reflect.Type {
-	switch t.Kind() {
-	case reflect.Pointer:
-		return derefType(t.Elem())
-	}
-	return t
-}
-
 func recoverError(rvr any) error {
 	var err error
 	switch e := rvr.(type) {
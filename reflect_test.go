@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/DataDog/jsonapi/internal/is"
+	"github.com/DataDog/jsonapi/internal/schema"
 )
 
 func TestDerefValue(t *testing.T) {
@@ -94,7 +95,7 @@ func TestDerefType(t *testing.T) {
 			description: "string -> string",
 			do: func() reflect.Type {
 				var s string
-				return derefType(reflect.TypeOf(s))
+				return schema.DerefType(reflect.TypeOf(s))
 			},
 			expectKind: "string",
 			expectType: "string",
@@ -102,7 +103,7 @@ func TestDerefType(t *testing.T) {
 			description: "*string -> string",
 			do: func() reflect.Type {
 				var s string
-				return derefType(reflect.TypeOf(&s))
+				return schema.DerefType(reflect.TypeOf(&s))
 			},
 			expectKind: "string",
 			expectType: "string",
@@ -110,7 +111,7 @@ func TestDerefType(t *testing.T) {
 			description: "**string -> string",
 			do: func() reflect.Type {
 				var s *string
-				return derefType(reflect.TypeOf(&s))
+				return schema.DerefType(reflect.TypeOf(&s))
 			},
 			expectKind: "string",
 			expectType: "string",
@@ -118,7 +119,7 @@ func TestDerefType(t *testing.T) {
 			description: "Article -> Article",
 			do: func() reflect.Type {
 				var a Article
-				return derefType(reflect.TypeOf(a))
+				return schema.DerefType(reflect.TypeOf(a))
 			},
 			expectKind: "struct",
 			expectType: "jsonapi.Article",
@@ -126,7 +127,7 @@ func TestDerefType(t *testing.T) {
 			description: "*Article -> Article",
 			do: func() reflect.Type {
 				var a Article
-				return derefType(reflect.TypeOf(&a))
+				return schema.DerefType(reflect.TypeOf(&a))
 			},
 			expectKind: "struct",
 			expectType: "jsonapi.Article",
@@ -134,7 +135,7 @@ func TestDerefType(t *testing.T) {
 			description: "**Article -> Article",
 			do: func() reflect.Type {
 				var a *Article
-				return derefType(reflect.TypeOf(&a))
+				return schema.DerefType(reflect.TypeOf(&a))
 			},
 			expectKind: "struct",
 			expectType: "jsonapi.Article",
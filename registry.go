@@ -0,0 +1,182 @@
+package jsonapi
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// registry maps resource types to the types resource objects of that type are unmarshaled into,
+// when the target of unmarshaling is an interface (e.g. a polymorphic relationship).
+type registry map[string]reflect.Type
+
+// registeredTypes is the registry of RegisterType.
+var registeredTypes = struct {
+	sync.RWMutex
+	types registry
+}{types: make(registry)}
+
+// RegisterType registers the type of v, a struct or a pointer to a struct whose primary field has
+// the given resource type, for unmarshaling resource objects of that type into interfaces.
+//
+// Primary data unmarshaled into an interface, or into a slice of interfaces like []any, is made
+// of values of the types registered for the type of each resource object.
+//
+// A relationship field typed as an interface (e.g. `Owner Principal`) is set to a value of the
+// type registered for the type of its resource linkage, which is the type of v (e.g. Person or
+// *Person). A pointer is used instead of a struct which only implements the interface through its
+// pointer. Marshaling works on such fields as long as they hold a resource struct.
+//
+// RegisterType is meant to be called during initialization, and panics if the resource type is
+// already registered or doesn't match the primary field of v. See UnmarshalRegisterType to
+// register types for some calls only.
+func RegisterType(resourceType string, v any) {
+	t, err := registryType(resourceType, v)
+	if err != nil {
+		panic(err)
+	}
+
+	registeredTypes.Lock()
+	defer registeredTypes.Unlock()
+
+	if rt, ok := registeredTypes.types[resourceType]; ok {
+		panic(fmt.Sprintf("jsonapi: resource type %q is already registered to %s", resourceType, rt))
+	}
+	registeredTypes.types[resourceType] = t
+}
+
+// UnmarshalRegisterType registers the type of v for the given resource type like RegisterType,
+// for the Unmarshaler only. Types registered this way take precedence over the ones registered
+// with RegisterType. It panics if the resource type doesn't match the primary field of v.
+func UnmarshalRegisterType(resourceType string, v any) UnmarshalOption {
+	t, err := registryType(resourceType, v)
+	if err != nil {
+		panic(err)
+	}
+
+	return func(m *Unmarshaler) {
+		// the registry is copied, since it may be shared with the Unmarshaler this one derives from
+		types := make(registry, len(m.types)+1)
+		for rt, t := range m.types {
+			types[rt] = t
+		}
+		types[resourceType] = t
+		m.types = types
+	}
+}
+
+// registryType returns the type of v after checking that it is a struct, or a pointer to one,
+// whose primary field has the given resource type.
+func registryType(resourceType string, v any) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	st := t
+	if st.Kind() == reflect.Pointer {
+		st = st.Elem()
+	}
+	if st.Kind() != reflect.Struct {
+		return nil, &TypeError{Actual: t.String(), Expected: []string{"struct", "*struct"}}
+	}
+
+	fields, err := schema.CachedTypeFields(st)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f.Tag.Directive != schema.Primary {
+			continue
+		}
+		if f.Tag.ResourceType != resourceType {
+			return nil, &TypeError{Actual: f.Tag.ResourceType, Expected: []string{resourceType}}
+		}
+		return t, nil
+	}
+
+	return nil, ErrMissingPrimaryField
+}
+
+// lookupType returns the type registered for the given resource type, if any.
+func (m *Unmarshaler) lookupType(resourceType string) (reflect.Type, bool) {
+	if t, ok := m.types[resourceType]; ok {
+		return t, true
+	}
+
+	registeredTypes.RLock()
+	defer registeredTypes.RUnlock()
+
+	t, ok := registeredTypes.types[resourceType]
+	return t, ok
+}
+
+// implementingTypes returns the sorted resource types whose registered types implement it.
+func (m *Unmarshaler) implementingTypes(it reflect.Type) []string {
+	seen := make(map[string]bool)
+	var resourceTypes []string
+
+	add := func(types registry) {
+		for rt, t := range types {
+			if !seen[rt] && implementation(t, it).IsValid() {
+				resourceTypes = append(resourceTypes, rt)
+			}
+			seen[rt] = true
+		}
+	}
+
+	add(m.types)
+	registeredTypes.RLock()
+	add(registeredTypes.types)
+	registeredTypes.RUnlock()
+
+	sort.Strings(resourceTypes)
+	return resourceTypes
+}
+
+// implementation returns a new value of the registered type t which implements it: a t if it
+// does, otherwise a pointer to a t if it does, otherwise the zero Value.
+func implementation(t, it reflect.Type) reflect.Value {
+	switch {
+	case t.Kind() == reflect.Pointer && t.AssignableTo(it):
+		return reflect.New(t.Elem())
+	case t.AssignableTo(it):
+		return reflect.New(t).Elem()
+	case reflect.PointerTo(t).AssignableTo(it):
+		return reflect.New(t)
+	default:
+		return reflect.Value{}
+	}
+}
+
+// unmarshalInterface unmarshals the resource object into iv, an interface value, using the type
+// registered for its resource type.
+func (ro *resourceObject) unmarshalInterface(iv reflect.Value, m *Unmarshaler) error {
+	var impl reflect.Value
+	if t, ok := m.lookupType(ro.Type); ok {
+		impl = implementation(t, iv.Type())
+	}
+	if impl.IsValid() && impl.Kind() == reflect.Pointer {
+		// reuse the value the resource was already unmarshaled into, if identities are preserved
+		if p, ok := m.identities.lookup(ro, impl.Type()); ok {
+			impl = p
+		}
+	}
+	if !impl.IsValid() {
+		expected := m.implementingTypes(iv.Type())
+		if len(expected) == 0 {
+			expected = []string{iv.Type().String()}
+		}
+		return &TypeError{Actual: ro.Type, Expected: expected}
+	}
+
+	target := impl
+	if target.Kind() != reflect.Pointer {
+		target = target.Addr()
+	}
+	if err := ro.unmarshal(target.Interface(), m); err != nil {
+		return err
+	}
+
+	iv.Set(impl)
+	return nil
+}
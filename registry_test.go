@@ -0,0 +1,239 @@
+package jsonapi
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func init() {
+	RegisterType("users", User{})
+}
+
+func TestRegisterType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description  string
+		resourceType string
+		given        any
+		expectPanic  any
+	}{
+		{
+			description:  "already registered",
+			resourceType: "users",
+			given:        &User{},
+			expectPanic:  `jsonapi: resource type "users" is already registered to jsonapi.User`,
+		}, {
+			description:  "mismatched resource type",
+			resourceType: "people",
+			given:        User{},
+			expectPanic:  &TypeError{Actual: "users", Expected: []string{"people"}},
+		}, {
+			description:  "missing primary field",
+			resourceType: "metadata",
+			given:        Metadata{},
+			expectPanic:  ErrMissingPrimaryField,
+		}, {
+			description:  "not a struct",
+			resourceType: "users",
+			given:        "users",
+			expectPanic:  &TypeError{Actual: "string", Expected: []string{"struct", "*struct"}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			defer func() {
+				is.Equal(t, tc.expectPanic, recover())
+			}()
+			RegisterType(tc.resourceType, tc.given)
+		})
+	}
+}
+
+func TestUnmarshalPolymorphicRelationships(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		opts        []UnmarshalOption
+		expect      *Project
+		expectError error
+	}{
+		{
+			description: "globally registered struct type",
+			given:       projectOwnedByUserBody,
+			expect:      &Project{ID: "1", Owner: User{ID: "1"}, Watchers: []Principal{}},
+		}, {
+			description: "pointer implementing the interface and nested included relationships",
+			given:       projectOwnedByTeamBody,
+			opts:        []UnmarshalOption{UnmarshalRegisterType("teams", Team{})},
+			expect: &Project{
+				ID:       "1",
+				Owner:    &Team{ID: "1", Name: "T", Members: []Principal{User{ID: "2", Name: "B"}}},
+				Watchers: []Principal{User{ID: "2", Name: "B"}, &Team{ID: "1", Name: "T", Members: []Principal{User{ID: "2", Name: "B"}}}},
+			},
+		}, {
+			description: "null",
+			given:       projectWithoutOwnerBody,
+			expect:      &Project{ID: "1"},
+		}, {
+			description: "unregistered type",
+			given:       projectOwnedByTeamBody,
+			expectError: &TypeError{Actual: "teams", Expected: []string{"users"}},
+		}, {
+			description: "type not implementing the interface",
+			given:       projectOwnedByUnknownBody,
+			opts: []UnmarshalOption{UnmarshalRegisterType("bots", struct {
+				ID string `jsonapi:"primary,bots"`
+			}{})},
+			expectError: &TypeError{Actual: "bots", Expected: []string{"users"}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			var p Project
+			err := Unmarshal([]byte(tc.given), &p, tc.opts...)
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, &p)
+
+			// polymorphic relationships are marshaled like any other
+			b, err := Marshal(&p)
+			is.MustNoError(t, err)
+			expect, err := Marshal(tc.expect)
+			is.MustNoError(t, err)
+			is.EqualJSON(t, string(expect), string(b))
+		})
+	}
+}
+
+func TestUnmarshalHeterogeneousPrimaryData(t *testing.T) {
+	t.Parallel()
+
+	principals := []Principal{
+		User{ID: "1", Name: "A"},
+		&Team{ID: "1", Name: "T", Members: []Principal{User{ID: "2", Name: "B"}}},
+	}
+
+	tests := []struct {
+		description string
+		given       string
+		do          func(body []byte) (any, error)
+		expect      any
+		expectError error
+	}{
+		{
+			description: "[]any",
+			given:       principalsBody,
+			do: func(body []byte) (any, error) {
+				var v []any
+				err := Unmarshal(body, &v, UnmarshalRegisterType("teams", Team{}))
+				return v, err
+			},
+			// Team is registered as a struct, and only *Team implements Principal
+			expect: []any{principals[0], Team{ID: "1", Name: "T", Members: []Principal{User{ID: "2", Name: "B"}}}},
+		}, {
+			description: "[]Principal",
+			given:       principalsBody,
+			do: func(body []byte) (any, error) {
+				var v []Principal
+				err := Unmarshal(body, &v, UnmarshalRegisterType("teams", Team{}))
+				return v, err
+			},
+			expect: principals,
+		}, {
+			description: "Principal",
+			given:       `{"data":{"id":"1","type":"users","attributes":{"name":"A"}}}`,
+			do: func(body []byte) (any, error) {
+				var v Principal
+				err := Unmarshal(body, &v)
+				return v, err
+			},
+			expect: principals[0],
+		}, {
+			description: "Document[[]Principal]",
+			given:       principalsBody,
+			do: func(body []byte) (any, error) {
+				doc, err := UnmarshalDocument[[]Principal](body, UnmarshalRegisterType("teams", Team{}))
+				if err != nil {
+					return nil, err
+				}
+				return doc.Data, nil
+			},
+			expect: principals,
+		}, {
+			description: "Decoder.Next",
+			given:       principalsBody,
+			do: func(body []byte) (any, error) {
+				dec := NewDecoder(bytes.NewReader(body), UnmarshalRegisterType("teams", Team{}))
+
+				var v []Principal
+				for {
+					var p Principal
+					err := dec.Next(&p)
+					if err == io.EOF {
+						return v, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					v = append(v, p)
+				}
+			},
+			// included resources aren't aliased when streaming
+			expect: []Principal{principals[0], &Team{ID: "1", Name: "T", Members: []Principal{User{ID: "2"}}}},
+		}, {
+			description: "unregistered type",
+			given:       principalsBody,
+			do: func(body []byte) (any, error) {
+				var v []any
+				err := Unmarshal(body, &v)
+				return v, err
+			},
+			expectError: &TypeError{Actual: "teams", Expected: []string{"users"}},
+		}, {
+			description: "type not implementing the interface",
+			given:       principalsWithProjectBody,
+			do: func(body []byte) (any, error) {
+				var v []Principal
+				err := Unmarshal(body, &v, UnmarshalRegisterType("projects", Project{}))
+				return v, err
+			},
+			expectError: &TypeError{Actual: "projects", Expected: []string{"users"}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := tc.do([]byte(tc.given))
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+		})
+	}
+}
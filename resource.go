@@ -0,0 +1,393 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// Resource is a resource object as defined by https://jsonapi.org/format/1.0/#document-resource-objects,
+// for processing documents without Go types describing their resources (e.g. in a proxy).
+//
+// A *Resource can be marshaled anywhere a resource struct can, like the primary data of a Document
+// or the values given to MarshalInclude, and goes through the same document validation.
+type Resource struct {
+	ro *resourceObject
+
+	// parsedLinks is true if the links object was unmarshaled rather than set with SetLinks, in which
+	// case it's marshaled as is (e.g. it may only hold links like "describedby" which Link leaves out)
+	parsedLinks bool
+}
+
+// NewResource returns a resource with the given type and id, and no other members.
+func NewResource(resourceType, id string) *Resource {
+	return &Resource{ro: &resourceObject{
+		ID:            id,
+		Type:          resourceType,
+		Attributes:    make(map[string]any),
+		Relationships: make(map[string]*document),
+	}}
+}
+
+// newResource returns a resource holding a copy of the unmarshaled resource object ro, whose
+// attributes and meta are decoded.
+func newResource(ro *resourceObject) (*Resource, error) {
+	r := NewResource(ro.Type, ro.ID)
+
+	links, err := decodeLink(ro.Links)
+	if err != nil {
+		return nil, err
+	}
+	r.ro.Links = links
+	r.parsedLinks = links != nil
+
+	if err := decodeGeneric(ro.rawAttributes, &r.ro.Attributes); err != nil {
+		return nil, err
+	}
+	if r.ro.Attributes == nil {
+		r.ro.Attributes = make(map[string]any)
+	}
+
+	meta, err := decodeGenericMeta(ro.Meta)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		r.ro.Meta = meta
+	}
+
+	for name, rd := range ro.Relationships {
+		rel, err := newRelationship(rd)
+		if err != nil {
+			return nil, err
+		}
+		r.ro.Relationships[name] = rel.d
+	}
+
+	return r, nil
+}
+
+// unmarshalDynamic validates d like unmarshalDocument does, and stores its primary data in v as
+// nil, a *Resource or a []*Resource.
+func (m *Unmarshaler) unmarshalDynamic(d *document, v *any) error {
+	if m.checkUniqueness {
+		if ok := d.verifyResourceUniqueness(); !ok {
+			return ErrNonuniqueResource
+		}
+	}
+	// linkage isn't aliased to the included resource objects, which are kept apart
+	if err := d.verifyFullLinkage(false); err != nil {
+		return err
+	}
+
+	if d.hasMany {
+		rs := make([]*Resource, 0, len(d.DataMany))
+		for _, ro := range d.DataMany {
+			r, err := newResource(ro)
+			if err != nil {
+				return err
+			}
+			rs = append(rs, r)
+		}
+		*v = rs
+	} else if d.DataOne != nil {
+		r, err := newResource(d.DataOne)
+		if err != nil {
+			return err
+		}
+		*v = r
+	}
+
+	return d.unmarshalOptionalFields(m)
+}
+
+// decodeGeneric decodes the given JSON, if any, into v with numbers decoded as json.Number.
+func decodeGeneric(b []byte, v any) error {
+	if len(b) == 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// decodeGenericMeta returns an unmarshaled meta object decoded as a map, or nil if it is not set.
+func decodeGenericMeta(meta any) (map[string]any, error) {
+	if meta == nil {
+		return nil, nil
+	}
+	b, err := rawMeta(meta)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]any
+	if err := decodeGeneric(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// decodeLink returns a copy of the unmarshaled links object l, whose link objects are decoded as
+// *LinkObject values so that it can be marshaled again.
+func decodeLink(l *Link) (*Link, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	decoded := *l
+	for _, lv := range []*any{&decoded.Self, &decoded.Related} {
+		if _, ok := (*lv).(map[string]any); !ok {
+			continue
+		}
+		b, err := json.Marshal(*lv)
+		if err != nil {
+			return nil, err
+		}
+		var lo LinkObject
+		if err := decodeGeneric(b, &lo); err != nil {
+			return nil, err
+		}
+		*lv = &lo
+	}
+
+	return &decoded, nil
+}
+
+// MarshalJSON implements the json.Marshaler interface.
+func (r *Resource) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.ro)
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (r *Resource) UnmarshalJSON(data []byte) error {
+	var ro resourceObject
+	if err := json.Unmarshal(data, &ro); err != nil {
+		return err
+	}
+
+	nr, err := newResource(&ro)
+	if err != nil {
+		return err
+	}
+	*r = *nr
+
+	return nil
+}
+
+// Type returns the type of the resource.
+func (r *Resource) Type() string {
+	return r.ro.Type
+}
+
+// ID returns the id of the resource.
+func (r *Resource) ID() string {
+	return r.ro.ID
+}
+
+// SetID sets the id of the resource.
+func (r *Resource) SetID(id string) {
+	r.ro.ID = id
+}
+
+// Identifier returns the resource identifier object of the resource.
+func (r *Resource) Identifier() ResourceIdentifier {
+	return ResourceIdentifier{Type: r.ro.Type, ID: r.ro.ID}
+}
+
+// Attributes returns the attributes of the resource, which may be modified in place. Attributes
+// decoded by ParseDocument hold generic JSON values, where numbers are json.Number values so that
+// they keep their precision.
+func (r *Resource) Attributes() map[string]any {
+	return r.ro.Attributes
+}
+
+// Attribute returns the value of the attribute with the given name, and whether it is present.
+func (r *Resource) Attribute(name string) (any, bool) {
+	v, ok := r.ro.Attributes[name]
+	return v, ok
+}
+
+// SetAttribute sets the value of the attribute with the given name.
+func (r *Resource) SetAttribute(name string, value any) {
+	r.ro.Attributes[name] = value
+}
+
+// DeleteAttribute removes the attribute with the given name.
+func (r *Resource) DeleteAttribute(name string) {
+	delete(r.ro.Attributes, name)
+}
+
+// RelationshipNames returns the sorted names of the relationships of the resource.
+func (r *Resource) RelationshipNames() []string {
+	names := make([]string, 0, len(r.ro.Relationships))
+	for name := range r.ro.Relationships {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Relationship returns the relationship with the given name, and whether it is present.
+func (r *Resource) Relationship(name string) (*Relationship, bool) {
+	d, ok := r.ro.Relationships[name]
+	if !ok {
+		return nil, false
+	}
+	return &Relationship{d: d}, true
+}
+
+// SetRelationship sets the relationship with the given name.
+func (r *Resource) SetRelationship(name string, rel *Relationship) {
+	r.ro.Relationships[name] = rel.d
+}
+
+// DeleteRelationship removes the relationship with the given name.
+func (r *Resource) DeleteRelationship(name string) {
+	delete(r.ro.Relationships, name)
+}
+
+// Meta returns the meta object of the resource, or nil if it has none.
+func (r *Resource) Meta() map[string]any {
+	m, _ := r.ro.Meta.(map[string]any)
+	return m
+}
+
+// SetMeta sets the meta object of the resource, which is removed if meta is nil.
+func (r *Resource) SetMeta(meta map[string]any) {
+	r.ro.Meta = nil
+	if meta != nil {
+		r.ro.Meta = meta
+	}
+}
+
+// Links returns the links object of the resource, or nil if it has none.
+func (r *Resource) Links() *Link {
+	return r.ro.Links
+}
+
+// SetLinks sets the links object of the resource, which is removed if link is nil.
+func (r *Resource) SetLinks(link *Link) {
+	r.ro.Links = link
+	r.parsedLinks = false
+}
+
+// ResourceIdentifier is a resource identifier object as defined by
+// https://jsonapi.org/format/1.0/#document-resource-identifier-objects.
+type ResourceIdentifier struct {
+	Type string
+	ID   string
+}
+
+// Relationship is a relationship object of a Resource as defined by
+// https://jsonapi.org/format/1.0/#document-resource-object-relationships.
+type Relationship struct {
+	d *document
+}
+
+// NewToOneRelationship returns a to-one relationship whose resource linkage is the given resource
+// identifier, or null if it is nil.
+func NewToOneRelationship(linkage *ResourceIdentifier) *Relationship {
+	d := &document{isRelationship: true}
+	if linkage != nil {
+		d.DataOne = &resourceObject{Type: linkage.Type, ID: linkage.ID}
+	}
+	return &Relationship{d: d}
+}
+
+// NewToManyRelationship returns a to-many relationship whose resource linkage is made of the
+// given resource identifiers.
+func NewToManyRelationship(linkage ...ResourceIdentifier) *Relationship {
+	d := &document{isRelationship: true, hasMany: true, DataMany: make([]*resourceObject, 0, len(linkage))}
+	for _, ri := range linkage {
+		d.DataMany = append(d.DataMany, &resourceObject{Type: ri.Type, ID: ri.ID})
+	}
+	return &Relationship{d: d}
+}
+
+// newRelationship returns a relationship holding a copy of the unmarshaled relationship document rd.
+func newRelationship(rd *document) (*Relationship, error) {
+	linkage := rd.getResourceObjectSlice()
+
+	links, err := decodeLink(rd.Links)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &document{
+		isRelationship: true,
+		hasMany:        rd.hasMany,
+		omitData:       rd.omitData,
+		Links:          links,
+		DataMany:       make([]*resourceObject, 0, len(linkage)),
+	}
+
+	meta, err := decodeGenericMeta(rd.Meta)
+	if err != nil {
+		return nil, err
+	}
+	if meta != nil {
+		d.Meta = meta
+	}
+
+	for _, ro := range linkage {
+		ri := &resourceObject{Type: ro.Type, ID: ro.ID}
+		meta, err := decodeGenericMeta(ro.Meta)
+		if err != nil {
+			return nil, err
+		}
+		if meta != nil {
+			ri.Meta = meta
+		}
+		if rd.hasMany {
+			d.DataMany = append(d.DataMany, ri)
+		} else {
+			d.DataOne = ri
+		}
+	}
+
+	return &Relationship{d: d}, nil
+}
+
+// IsToMany reports whether the relationship is a to-many relationship.
+func (rel *Relationship) IsToMany() bool {
+	return rel.d.hasMany
+}
+
+// HasLinkage reports whether the relationship has a "data" member, which may be null or empty.
+func (rel *Relationship) HasLinkage() bool {
+	return !rel.d.omitData
+}
+
+// Linkage returns the resource identifiers of the relationship linkage.
+func (rel *Relationship) Linkage() []ResourceIdentifier {
+	ros := rel.d.getResourceObjectSlice()
+	linkage := make([]ResourceIdentifier, len(ros))
+	for i, ro := range ros {
+		linkage[i] = ResourceIdentifier{Type: ro.Type, ID: ro.ID}
+	}
+	return linkage
+}
+
+// Meta returns the meta object of the relationship, or nil if it has none.
+func (rel *Relationship) Meta() map[string]any {
+	m, _ := rel.d.Meta.(map[string]any)
+	return m
+}
+
+// SetMeta sets the meta object of the relationship, which is removed if meta is nil.
+func (rel *Relationship) SetMeta(meta map[string]any) {
+	rel.d.Meta = nil
+	if meta != nil {
+		rel.d.Meta = meta
+	}
+}
+
+// Links returns the links object of the relationship, or nil if it has none.
+func (rel *Relationship) Links() *Link {
+	return rel.d.Links
+}
+
+// SetLinks sets the links object of the relationship, which is removed if link is nil.
+func (rel *Relationship) SetLinks(link *Link) {
+	rel.d.Links = link
+}
@@ -0,0 +1,230 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestParseDocument(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		opts        []UnmarshalOption
+		expectError error
+	}{
+		{
+			description: "null data",
+			given:       nullDataBody,
+		}, {
+			description: "empty many",
+			given:       emptyManyBody,
+		}, {
+			description: "single resource",
+			given:       articleABody,
+		}, {
+			description: "many resources",
+			given:       articlesABBody,
+		}, {
+			description: "resource meta and links",
+			given:       articleALinkedBody,
+		}, {
+			description: "nested attributes",
+			given:       articleCompleteBody,
+		}, {
+			description: "empty relationships",
+			given:       articleRelatedNoOmitEmptyBody,
+		}, {
+			description: "relationship without data",
+			given:       articleRelatedAuthorLinksOnlyBody,
+		}, {
+			description: "relationship with meta",
+			given:       articleRelatedAuthorWithMetaBody,
+		}, {
+			description: "compound document",
+			given:       articlesRelatedComplexBody,
+		}, {
+			description: "top-level meta",
+			given:       articleAToplevelMetaBody,
+		}, {
+			description: "errors",
+			given:       errorsComplexStructBody,
+		}, {
+			description: "partial linkage",
+			given:       articleWithIncludeOnlyBody,
+			expectError: &PartialLinkageError{[]string{"{Type: author, ID: 1}"}},
+		}, {
+			description: "nonunique data",
+			given:       articlesABNonuniqueData,
+			opts:        []UnmarshalOption{UnmarshalCheckUniqueness()},
+			expectError: ErrNonuniqueResource,
+		}, {
+			description: "invalid member name",
+			given:       articleWithInvalidRelationshipAttributeNameIncludedBody,
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/included/0/attributes/na%me"},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			doc, err := ParseDocument([]byte(tc.given), tc.opts...)
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+
+			b, err := json.Marshal(doc)
+			is.MustNoError(t, err)
+			is.EqualJSON(t, tc.given, string(b))
+		})
+	}
+}
+
+func TestResource(t *testing.T) {
+	t.Parallel()
+
+	doc, err := ParseDocument([]byte(articleRelatedCompleteBody))
+	is.MustNoError(t, err)
+
+	r, ok := doc.Data.(*Resource)
+	is.Equal(t, true, ok)
+	is.Equal(t, ResourceIdentifier{Type: "articles", ID: "1"}, r.Identifier())
+	is.Equal(t, []string{"author", "comments"}, r.RelationshipNames())
+
+	title, ok := r.Attribute("title")
+	is.Equal(t, true, ok)
+	is.Equal(t, "A", title)
+
+	author, ok := r.Relationship("author")
+	is.Equal(t, true, ok)
+	is.Equal(t, false, author.IsToMany())
+	is.Equal(t, true, author.HasLinkage())
+	is.Equal(t, []ResourceIdentifier{{Type: "author", ID: "1"}}, author.Linkage())
+	is.Equal(t, map[string]any{"count": json.Number("10")}, author.Meta())
+
+	comments, ok := r.Relationship("comments")
+	is.Equal(t, true, ok)
+	is.Equal(t, true, comments.IsToMany())
+	is.Equal(t, []ResourceIdentifier{{Type: "comments", ID: "1"}, {Type: "comments", ID: "2"}}, comments.Linkage())
+
+	r.SetID("2")
+	r.SetAttribute("title", "B")
+	r.SetAttribute("views", json.Number("12345678901234567890"))
+	r.SetMeta(map[string]any{"foo": "bar"})
+	r.SetRelationship("author", NewToOneRelationship(nil))
+	r.SetRelationship("comments", NewToManyRelationship(ResourceIdentifier{Type: "comments", ID: "3"}))
+
+	b, err := MarshalDocument(doc)
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":{"id":"2","type":"articles","attributes":{"title":"B","views":12345678901234567890},"meta":{"foo":"bar"},"relationships":{"author":{"data":null},"comments":{"data":[{"id":"3","type":"comments"}]}}}}`, string(b))
+
+	r.DeleteAttribute("views")
+	r.DeleteRelationship("author")
+	r.DeleteRelationship("comments")
+	r.SetMeta(nil)
+
+	b, err = MarshalDocument(doc, MarshalFields(url.Values{"fields[articles]": {"title"}}))
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":{"id":"2","type":"articles","attributes":{"title":"B"}}}`, string(b))
+}
+
+func TestMarshalResource(t *testing.T) {
+	t.Parallel()
+
+	article := NewResource("articles", "1")
+	article.SetRelationship("author", NewToOneRelationship(&ResourceIdentifier{Type: "author", ID: "1"}))
+
+	author := NewResource("author", "1")
+	author.SetAttribute("name", "A")
+
+	tests := []struct {
+		description string
+		given       any
+		opts        []MarshalOption
+		expect      string
+		expectError error
+	}{
+		{
+			description: "resource",
+			given:       article,
+			expect:      `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"1","type":"author"}}}}}`,
+		}, {
+			description: "resources with include",
+			given:       []*Resource{article},
+			opts:        []MarshalOption{MarshalInclude(author)},
+			expect:      `{"data":[{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"1","type":"author"}}}}],"included":[{"id":"1","type":"author","attributes":{"name":"A"}}]}`,
+		}, {
+			description: "partial linkage",
+			given:       author,
+			opts:        []MarshalOption{MarshalInclude(article)},
+			expectError: &PartialLinkageError{[]string{"{Type: articles, ID: 1}"}},
+		}, {
+			description: "missing type",
+			given:       NewResource("", "1"),
+			expectError: ErrMissingPrimaryField,
+		}, {
+			description: "invalid attribute name",
+			given: func() *Resource {
+				r := NewResource("author", "1")
+				r.SetAttribute("na%me", "A")
+				return r
+			}(),
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/data/attributes/na%me"},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := Marshal(tc.given, tc.opts...)
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.EqualJSON(t, tc.expect, string(actual))
+		})
+	}
+}
+
+func TestResourceLinks(t *testing.T) {
+	t.Parallel()
+
+	// parsed links are marshaled as is, leaving out the links Link doesn't hold
+	doc, err := ParseDocument([]byte(`{"data":{"type":"articles","id":"1","links":{"describedby":"/schema"}}}`))
+	is.MustNoError(t, err)
+	b, err := MarshalDocument(doc)
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":{"type":"articles","id":"1"}}`, string(b))
+
+	doc, err = ParseDocument([]byte(`{"data":{"type":"articles","id":"1","links":{"self":"/articles/1"}}}`))
+	is.MustNoError(t, err)
+	b, err = MarshalDocument(doc)
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":{"type":"articles","id":"1","links":{"self":"/articles/1"}}}`, string(b))
+
+	// links which are set are checked, without being modified
+	r := NewResource("articles", "1")
+	r.SetLinks(&Link{Self: "", Related: "/articles/1/author"})
+	b, err = Marshal(r)
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":{"type":"articles","id":"1","links":{"related":"/articles/1/author"}}}`, string(b))
+	is.Equal(t, "", r.Links().Self)
+
+	r.SetLinks(&Link{})
+	_, err = Marshal(r)
+	is.Equal(t, ErrMissingLinkFields, err)
+}
@@ -0,0 +1,157 @@
+package jsonapi
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/jsonapi/internal/schema"
+)
+
+// Sort holds the fields of the sort query parameter in order, as defined by
+// https://jsonapi.org/format/1.0/#fetching-sorting.
+type Sort []SortField
+
+// SortField is a field of the sort query parameter.
+type SortField struct {
+	// Field is the name of the field, e.g. "created" or "title".
+	Field string
+
+	// Descending is true if the field is prefixed with a minus (e.g. "-created").
+	Descending bool
+}
+
+// ParseSort parses the value of the sort query parameter (e.g. "-created,title"). An empty value
+// has no sort fields, while an empty field is an error like the ones of ParseQuery.
+func ParseSort(value string) (Sort, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	var s Sort
+	for _, field := range strings.Split(value, ",") {
+		sf := SortField{Field: strings.TrimSpace(field)}
+		if strings.HasPrefix(sf.Field, "-") {
+			sf.Field = sf.Field[1:]
+			sf.Descending = true
+		}
+		if sf.Field == "" {
+			return nil, queryError("sort", "%q has an empty sort field", value)
+		}
+		s = append(s, sf)
+	}
+	return s, nil
+}
+
+// String returns the value of the sort query parameter holding s.
+func (s Sort) String() string {
+	fields := make([]string, len(s))
+	for i, sf := range s {
+		fields[i] = sf.Field
+		if sf.Descending {
+			fields[i] = "-" + sf.Field
+		}
+	}
+	return strings.Join(fields, ",")
+}
+
+// Check returns an error if a sort field isn't the member name of an attribute of v, a struct or a
+// pointer to one, which is then an *Error like the ones of ParseQuery. Servers must reject sort
+// fields they don't support.
+func (s Sort) Check(v any) error {
+	t := schema.DerefType(reflect.TypeOf(v))
+	for _, sf := range s {
+		if _, err := sortAttribute(t, sf.Field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply sorts the resources of the slice v, or of the slice pointed to by v, by the attributes
+// named by the sort fields, keeping the order of equal resources. The elements of v must be structs
+// or pointers to structs, and the sorted attributes must hold (pointers to) booleans, numbers,
+// strings or time.Time values. Nil pointers sort first in ascending fields and last in descending
+// ones.
+func (s Sort) Apply(v any) error {
+	sv := derefValue(reflect.ValueOf(v))
+	if sv.Kind() != reflect.Slice {
+		return &TypeError{Actual: sv.Type().String(), Expected: []string{"slice"}}
+	}
+	et := schema.DerefType(sv.Type().Elem())
+
+	fields := make([]*schema.Field, len(s))
+	for i, sf := range s {
+		f, err := sortAttribute(et, sf.Field)
+		if err != nil {
+			return err
+		}
+		if !schema.IsComparable(schema.DerefType(f.Type)) {
+			return &TypeError{Actual: f.Type.String(), Expected: []string{"bool", "number", "string", "time.Time"}}
+		}
+		fields[i] = f
+	}
+
+	// values are read before sorting, since swapping them would move them
+	values := make([][]reflect.Value, sv.Len())
+	for i := range values {
+		values[i] = make([]reflect.Value, len(fields))
+		ev := sv.Index(i)
+		for ev.Kind() == reflect.Pointer && !ev.IsNil() {
+			ev = ev.Elem()
+		}
+		if ev.Kind() != reflect.Struct {
+			// nil resources sort first
+			continue
+		}
+		for j, f := range fields {
+			fv, ok := schema.FieldByIndex(ev, f.Index, false)
+			if ok {
+				values[i][j] = fv
+			}
+		}
+	}
+
+	order := make([]int, sv.Len())
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		for j, sf := range s {
+			c := schema.Compare(values[order[a]][j], values[order[b]][j])
+			if c == 0 {
+				continue
+			}
+			if sf.Descending {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+
+	sorted := reflect.MakeSlice(sv.Type(), sv.Len(), sv.Len())
+	for i, j := range order {
+		sorted.Index(i).Set(sv.Index(j))
+	}
+	reflect.Copy(sv, sorted)
+
+	return nil
+}
+
+// sortAttribute returns the attribute field of the struct type t with the given member name.
+func sortAttribute(t reflect.Type, name string) (*schema.Field, error) {
+	if t.Kind() == reflect.Struct {
+		fields, err := schema.CachedTypeFields(t)
+		if err != nil {
+			return nil, err
+		}
+		for i := range fields {
+			f := &fields[i]
+			if f.Tag.Directive == schema.Attribute && f.Exported && f.MemberName == name {
+				return f, nil
+			}
+		}
+	}
+	return nil, queryError("sort", "%q is not a sortable field", name)
+}
@@ -0,0 +1,161 @@
+package jsonapi
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestParseSort(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		expect      Sort
+		expectError error
+	}{
+		{
+			description: "empty",
+			given:       "",
+			expect:      nil,
+		}, {
+			description: "directions",
+			given:       "-created,title",
+			expect:      Sort{{Field: "created", Descending: true}, {Field: "title"}},
+		}, {
+			description: "empty field",
+			given:       "title,-",
+			expectError: queryError("sort", `"title,-" has an empty sort field`),
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := ParseSort(tc.given)
+			if tc.expectError != nil {
+				is.Equal(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+			is.Equal(t, tc.given, actual.String())
+		})
+	}
+}
+
+func TestSortCheck(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		expectError error
+	}{
+		{
+			description: "attributes",
+			given:       "-priority,name",
+		}, {
+			description: "unknown field",
+			given:       "created",
+			expectError: queryError("sort", `"created" is not a sortable field`),
+		}, {
+			description: "relationship",
+			given:       "author",
+			expectError: queryError("sort", `"author" is not a sortable field`),
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			s, err := ParseSort(tc.given)
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expectError, s.Check(&Event{}))
+		})
+	}
+}
+
+func TestSortApply(t *testing.T) {
+	t.Parallel()
+
+	day := func(d int) *time.Time {
+		t := time.Date(2022, 1, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	events := []*Event{
+		{ID: "1", Name: "b", Priority: 1, Start: day(2)},
+		{ID: "2", Name: "a", Priority: 2, Done: true},
+		{ID: "3", Name: "c", Priority: 1, Start: day(1)},
+		{ID: "4", Name: "a", Priority: 2, Start: day(3)},
+	}
+
+	tests := []struct {
+		description string
+		given       string
+		expect      []string
+		expectError error
+	}{
+		{
+			description: "string",
+			given:       "name",
+			expect:      []string{"2", "4", "1", "3"},
+		}, {
+			description: "descending int then string",
+			given:       "-priority,-name",
+			expect:      []string{"2", "4", "3", "1"},
+		}, {
+			description: "time pointers with nil first",
+			given:       "start",
+			expect:      []string{"2", "3", "1", "4"},
+		}, {
+			description: "bool",
+			given:       "-done",
+			expect:      []string{"2", "1", "3", "4"},
+		}, {
+			description: "not sortable",
+			given:       "tags",
+			expectError: &TypeError{Actual: "[]string", Expected: []string{"bool", "number", "string", "time.Time"}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			s, err := ParseSort(tc.given)
+			is.MustNoError(t, err)
+
+			// both slices of pointers and of structs are sorted
+			sorted := append([]*Event(nil), events...)
+			values := make([]Event, len(events))
+			for i, e := range events {
+				values[i] = *e
+			}
+
+			err = s.Apply(sorted)
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.MustNoError(t, s.Apply(&values))
+
+			for i, id := range tc.expect {
+				is.Equal(t, id, sorted[i].ID)
+				is.Equal(t, id, values[i].ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,682 @@
+package jsonapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// An Encoder writes json:api documents to an output stream.
+type Encoder struct {
+	w      io.Writer
+	m      *Marshaler
+	prefix string
+	indent string
+}
+
+// NewEncoder returns a new encoder that writes to w. The given options are applied to every
+// document written by Encode.
+func NewEncoder(w io.Writer, opts ...MarshalOption) *Encoder {
+	return &Encoder{w: w, m: NewMarshaler(opts...)}
+}
+
+// SetIndent instructs the encoder to format each subsequent encoded document as if indented by
+// the package function json.Indent(dst, src, prefix, indent).
+func (e *Encoder) SetIndent(prefix, indent string) {
+	e.prefix = prefix
+	e.indent = indent
+}
+
+// Encode writes the json:api encoding of v to the stream, followed by a newline character.
+// It performs the same document validation as Marshal.
+func (e *Encoder) Encode(v any) error {
+	b, err := e.m.marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if e.prefix != "" || e.indent != "" {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, b, e.prefix, e.indent); err != nil {
+			return err
+		}
+		b = buf.Bytes()
+	}
+
+	b = append(b, '\n')
+	_, err = e.w.Write(b)
+	return err
+}
+
+// Stream returns a StreamWriter which writes a single document to the output stream, whose primary
+// data is an array of resource objects written one at a time. SetIndent does not apply to it.
+func (e *Encoder) Stream() *StreamWriter {
+	return &StreamWriter{w: e.w, m: e.m, d: newDocument()}
+}
+
+// EncodeEach writes a document to the output stream whose primary data is made of the values
+// returned by next, which is called until it returns io.EOF. The given options are applied on top
+// of the Encoder's options, see StreamWriter.
+//
+// If next returns any other error, it is returned and the document written so far is incomplete.
+func (e *Encoder) EncodeEach(next func() (any, error), opts ...MarshalOption) error {
+	sw := &StreamWriter{w: e.w, m: e.m.with(opts), d: newDocument()}
+	for {
+		v, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if err := sw.Write(v); err != nil {
+			return err
+		}
+	}
+	return sw.Close()
+}
+
+// A StreamWriter writes a json:api document whose primary data is an array of resource objects,
+// without holding more than one of them in memory. It is created by Encoder.Stream.
+//
+// The document is validated like Marshal does, with the following differences:
+//   - Uniqueness of resources, if enabled by MarshallCheckUniqueness, is verified as each resource
+//     object is written, which requires keeping the identifiers of all of them.
+//   - Included resources of MarshalInclude, and resources reachable through MarshalIncludePaths,
+//     must be given to the Encoder. The former are linked to the resource objects as these are
+//     written, and the latter are gathered as each resource object is written. Both are written
+//     by Close.
+//   - Sparse fieldsets aren't validated by MarshalCheckFields.
+//
+// Once Write or Close returns an error, the document is incomplete and all further calls fail.
+type StreamWriter struct {
+	w io.Writer
+	m *Marshaler
+	d *document
+	n int
+
+	// seen holds the identifiers of the primary resource objects when uniqueness is checked
+	seen map[string]bool
+	// included holds the resource objects of MarshalInclude once they are made, and linked tells
+	// whether each of them is linked to by a primary resource object, to verify full-linkage
+	included []*resourceObject
+	linked   map[string]bool
+	// reachedIncluded holds the resource objects reached through include paths so far, and
+	// reached holds the identifiers of these and of the primary resource objects
+	reachedIncluded []*resourceObject
+	reached         map[string]bool
+
+	err error
+}
+
+// makeIncluded makes the resource objects of the included resources given by MarshalInclude, once.
+func (sw *StreamWriter) makeIncluded() error {
+	if sw.linked != nil {
+		return nil
+	}
+
+	sw.linked = make(map[string]bool, len(sw.m.included))
+	for _, v := range sw.m.included {
+		ro, err := sw.d.makeResourceObject(v, reflect.TypeOf(v), sw.m)
+		if err != nil {
+			return err
+		}
+		sw.included = append(sw.included, ro)
+		sw.linked[ro.getIdentifier()] = false
+	}
+	return nil
+}
+
+// Write writes the json:api encoding of v, which must be a struct, as the next resource object of
+// the primary data.
+func (sw *StreamWriter) Write(v any) (err error) {
+	if sw.err != nil {
+		return sw.err
+	}
+	defer func() {
+		// because we make use of reflect we must recover any panics
+		if rvr := recover(); rvr != nil {
+			err = recoverError(rvr)
+		}
+		if err != nil {
+			sw.err = err
+		}
+	}()
+
+	vt := reflect.TypeOf(v)
+	if vt == nil {
+		return &TypeError{Actual: "nil", Expected: []string{"struct"}}
+	}
+	if err := sw.makeIncluded(); err != nil {
+		return err
+	}
+
+	ro, err := sw.d.makeResourceObject(v, vt, sw.m)
+	if err != nil {
+		return err
+	}
+	rd := &document{DataOne: ro}
+	filterDocumentFieldsets(rd, sw.m)
+	rid := ro.getIdentifier()
+
+	if sw.m.checkUniqueness {
+		if sw.seen == nil {
+			sw.seen = make(map[string]bool)
+		}
+		if ro.ID != "" && sw.seen[rid] {
+			return ErrNonuniqueResource
+		}
+		sw.seen[rid] = true
+
+		if ok := rd.verifyResourceUniqueness(); !ok {
+			return ErrNonuniqueResource
+		}
+	}
+
+	if len(sw.m.includePaths) > 0 {
+		if sw.reached == nil {
+			sw.reached = make(map[string]bool)
+		}
+		if sw.reached[rid] {
+			// resources written as primary data after being reached through include paths aren't
+			// included
+			sw.reachedIncluded = removeResourceObject(sw.reachedIncluded, rid)
+		}
+		sw.reached[rid] = true
+		included, err := sw.d.includePathResources(v, sw.m, sw.reached)
+		if err != nil {
+			return err
+		}
+		sw.reachedIncluded = append(sw.reachedIncluded, included...)
+	}
+
+	if len(sw.included) > 0 {
+		for _, rel := range ro.Relationships {
+			for _, linkage := range rel.getResourceObjectSlice() {
+				if _, ok := sw.linked[linkage.getIdentifier()]; ok {
+					sw.linked[linkage.getIdentifier()] = true
+				}
+			}
+		}
+	}
+
+	if sw.m.memberNameValidationMode != DisableValidation {
+		if err := ro.validateMemberNames(sw.m.memberNameValidationMode, indexPath("/data", sw.n)); err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(ro)
+	if err != nil {
+		return err
+	}
+
+	sep := []byte(",")
+	if sw.n == 0 {
+		sep = []byte(`{"data":[`)
+	}
+	if _, err := sw.w.Write(append(sep, b...)); err != nil {
+		return err
+	}
+	sw.n++
+
+	return nil
+}
+
+// Close ends the primary data and writes the remaining top-level members of the document, followed
+// by a newline character. The given options are applied on top of the Encoder's options, which
+// allows for members like links to depend on the streamed resources (e.g. a next page cursor).
+// Included resources and include paths can't be given to Close, which returns ErrStreamIncludes.
+func (sw *StreamWriter) Close(opts ...MarshalOption) (err error) {
+	if sw.err != nil {
+		return sw.err
+	}
+	defer func() {
+		// because we make use of reflect we must recover any panics
+		if rvr := recover(); rvr != nil {
+			err = recoverError(rvr)
+		}
+		sw.err = err
+		if sw.err == nil {
+			sw.err = ErrStreamClosed
+		}
+	}()
+
+	if om := NewMarshaler(opts...); om.included != nil || om.includePaths != nil {
+		return ErrStreamIncludes
+	}
+	m := sw.m.with(opts)
+
+	if err := sw.makeIncluded(); err != nil {
+		return err
+	}
+
+	d := newDocument()
+	d.hasMany = true
+	d.Included = append(d.Included, sw.included...)
+	d.Included = append(d.Included, sw.reachedIncluded...)
+
+	if m.checkUniqueness {
+		for _, ro := range d.Included {
+			if ro.ID != "" && sw.seen[ro.getIdentifier()] {
+				return ErrNonuniqueResource
+			}
+		}
+		if ok := d.verifyResourceUniqueness(); !ok {
+			return ErrNonuniqueResource
+		}
+	}
+
+	if len(sw.included) > 0 {
+		// the included resources linked to by the primary resource objects, and the ones reached
+		// through include paths, are gathered into a single primary resource object, which is all
+		// verifyFullLinkage needs to traverse the included resources
+		var linkage []*resourceObject
+		for _, ro := range sw.included {
+			if sw.linked[ro.getIdentifier()] {
+				linkage = append(linkage, ro)
+			}
+		}
+		linkage = append(linkage, sw.reachedIncluded...)
+		d.DataMany = []*resourceObject{{
+			Relationships: map[string]*document{"": {hasMany: true, DataMany: linkage}},
+		}}
+		if err := d.verifyFullLinkage(false); err != nil {
+			return err
+		}
+		d.DataMany = nil
+	}
+
+	filterDocumentFieldsets(d, m)
+
+	if err := addOptionalDocumentFields(d, m); err != nil {
+		return err
+	}
+
+	if err := d.validateMemberNames(m.memberNameValidationMode, ""); err != nil {
+		return err
+	}
+
+	// marshal the document without its primary data, which has already been written
+	type alias document
+	b, err := json.Marshal(&struct{ *alias }{alias: (*alias)(d)})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if sw.n == 0 {
+		buf.WriteString(`{"data":[`)
+	}
+	buf.WriteString("]")
+	if len(b) > len("{}") {
+		buf.WriteString(",")
+	}
+	buf.Write(b[1:])
+	buf.WriteString("\n")
+
+	_, err = sw.w.Write(buf.Bytes())
+	return err
+}
+
+// removeResourceObject removes the resource object with the given identifier from ros.
+func removeResourceObject(ros []*resourceObject, identifier string) []*resourceObject {
+	for i, ro := range ros {
+		if ro.getIdentifier() == identifier {
+			return append(ros[:i], ros[i+1:]...)
+		}
+	}
+	return ros
+}
+
+// A Decoder reads and decodes json:api documents from an input stream.
+//
+// Documents can either be decoded as a whole with Decode, or incrementally with Next, which yields
+// the resource objects of the primary data one at a time.
+type Decoder struct {
+	r   io.Reader
+	m   *Unmarshaler
+	dec *json.Decoder
+
+	maxBytes        int64
+	skipFullLinkage bool
+	it              *resourceIterator
+}
+
+// NewDecoder returns a new decoder that reads from r. The given options are applied to every
+// document read by Decode.
+func NewDecoder(r io.Reader, opts ...UnmarshalOption) *Decoder {
+	return &Decoder{r: r, m: NewUnmarshaler(opts...)}
+}
+
+// DisallowUnknownFields causes the Decoder to return an error when a resource object contains
+// attributes which do not match any field of the destination struct.
+func (d *Decoder) DisallowUnknownFields() {
+	d.m.disallowUnknownFields = true
+}
+
+// SetMaxBytes limits the total number of bytes the Decoder reads from its input stream. Once the
+// limit is exceeded Decode returns ErrDocumentTooLarge. A limit of zero or less disables the check.
+// It must be called before the first call to Decode.
+func (d *Decoder) SetMaxBytes(n int64) {
+	d.maxBytes = n
+}
+
+// DisableFullLinkageCheck turns off the full-linkage check Next performs against the included
+// resources once the end of a document is reached.
+func (d *Decoder) DisableFullLinkageCheck() {
+	d.skipFullLinkage = true
+}
+
+func (d *Decoder) init() {
+	if d.dec != nil {
+		return
+	}
+
+	r := d.r
+	if d.maxBytes > 0 {
+		r = &maxBytesReader{r: r, n: d.maxBytes}
+	}
+	d.dec = json.NewDecoder(r)
+}
+
+// Decode reads the next json:api document from its input and stores it in the value pointed to by
+// v. It performs the same document validation as Unmarshal.
+func (d *Decoder) Decode(v any) error {
+	if d.it != nil {
+		return ErrDecoderIterating
+	}
+	d.init()
+
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+
+	return d.m.unmarshal(raw, v)
+}
+
+// Next reads the next resource object of the primary data of the current document and stores it
+// in the value pointed to by v, which must be a pointer to a struct, or to an interface holding
+// any registered type (see RegisterType) for heterogeneous primary data. When the primary data has been
+// exhausted, Next reads the remainder of the document and returns io.EOF. A subsequent call to
+// Next starts reading the next document in the stream.
+//
+// Unlike Decode, Next decodes a single primary resource object at a time:
+//   - Top-level meta and links requested via UnmarshalMeta and UnmarshalLinks are decoded once the
+//     end of the document is reached.
+//   - Relationship fields are only populated with resource linkage, included resources are not
+//     aliased into them.
+//   - Full-linkage and uniqueness checks are deferred until the end of the document. The resource
+//     linkage of the primary resource objects is kept for the full-linkage check, unless it is
+//     turned off with DisableFullLinkageCheck, and their identifiers are only kept for the
+//     uniqueness check of UnmarshalCheckUniqueness.
+//
+// If the document is an error document, Next returns a *DocumentError instead of io.EOF.
+func (d *Decoder) Next(v any) error {
+	d.init()
+
+	if d.it == nil {
+		if err := expectDelim(d.dec, '{'); err != nil {
+			return err
+		}
+		d.it = &resourceIterator{members: make(map[string]json.RawMessage)}
+	}
+
+	err := d.next(v)
+	if err != nil {
+		// the iterator is either done or in an unrecoverable state
+		d.it = nil
+	}
+	return err
+}
+
+func (d *Decoder) next(v any) error {
+	it := d.it
+
+	for {
+		if it.inData {
+			if d.dec.More() {
+				var raw json.RawMessage
+				if err := d.dec.Decode(&raw); err != nil {
+					return err
+				}
+				return it.unmarshalResourceObject(raw, v, d.m, !d.skipFullLinkage)
+			}
+
+			// consume the closing "]" of the primary data
+			if err := expectDelim(d.dec, ']'); err != nil {
+				return err
+			}
+			it.inData = false
+			continue
+		}
+
+		if !d.dec.More() {
+			// consume the closing "}" of the document
+			if err := expectDelim(d.dec, '}'); err != nil {
+				return err
+			}
+			if err := it.finish(d.m, !d.skipFullLinkage); err != nil {
+				return err
+			}
+			return io.EOF
+		}
+
+		t, err := d.dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := t.(string)
+		if !ok {
+			return fmt.Errorf("unexpected token %v", t)
+		}
+
+		if key != "data" {
+			var raw json.RawMessage
+			if err := d.dec.Decode(&raw); err != nil {
+				return err
+			}
+			it.members[key] = raw
+			continue
+		}
+
+		it.hasData = true
+		t, err = d.dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t {
+		case json.Delim('['):
+			it.inData = true
+		case json.Delim('{'):
+			// the primary data is a single resource object, so read it as a whole
+			raw, err := decodeObjectMembers(d.dec)
+			if err != nil {
+				return err
+			}
+			return it.unmarshalResourceObject(raw, v, d.m, !d.skipFullLinkage)
+		case nil:
+			// {"data":null, ...} is valid
+			continue
+		default:
+			return &TypeError{Actual: fmt.Sprintf("%T", t), Expected: []string{"object", "array", "null"}}
+		}
+	}
+}
+
+// expectDelim reads the next token from dec and returns an error if it is not the given delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("unexpected token %v, expected %v", t, delim)
+	}
+	return nil
+}
+
+// decodeObjectMembers reads the remaining members of a JSON object whose opening "{" has already
+// been consumed from dec, and returns the re-encoded object.
+func decodeObjectMembers(dec *json.Decoder) (json.RawMessage, error) {
+	members := make(map[string]json.RawMessage)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := t.(string)
+		if !ok {
+			return nil, fmt.Errorf("unexpected token %v", t)
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, err
+		}
+		members[key] = raw
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		// {"data":{}, ...} is invalid
+		return nil, ErrEmptyDataObject
+	}
+
+	return json.Marshal(members)
+}
+
+// resourceIterator holds the state of a Decoder reading a document with Next.
+type resourceIterator struct {
+	// inData is true while the Decoder is positioned within the primary data array
+	inData  bool
+	hasData bool
+
+	// n is the number of primary resource objects read so far
+	n int
+
+	// members holds the raw top-level members other than data
+	members map[string]json.RawMessage
+
+	// primary holds what the checks at the end of the document need of the primary resource
+	// objects read so far: their identifiers, and their resource linkage for the full-linkage check
+	primary []*resourceObject
+}
+
+func (it *resourceIterator) unmarshalResourceObject(raw json.RawMessage, v any, m *Unmarshaler, verifyLinkage bool) error {
+	path := "/data"
+	if it.inData {
+		path = indexPath(path, it.n)
+	}
+	it.n++
+	if err := validateJSONMemberNames(raw, m.memberNameValidationMode, path); err != nil {
+		return err
+	}
+
+	var ro resourceObject
+	if err := json.Unmarshal(raw, &ro); err != nil {
+		return err
+	}
+	if err := ro.validateTypeNames(m.memberNameValidationMode, path); err != nil {
+		return err
+	}
+
+	if verifyLinkage || m.checkUniqueness {
+		// only keep what the checks performed at the end of the document need
+		pro := &resourceObject{ID: ro.ID, Type: ro.Type}
+		if verifyLinkage {
+			pro.Relationships = ro.Relationships
+		}
+		it.primary = append(it.primary, pro)
+	}
+
+	return m.unmarshalResourceObject(&ro, v)
+}
+
+// finish validates the document once all of it has been read, and decodes optional top-level
+// members requested through m.
+func (it *resourceIterator) finish(m *Unmarshaler, verifyLinkage bool) error {
+	if it.hasData {
+		// the primary data has already been consumed, this satisfies the document's required members
+		it.members["data"] = json.RawMessage("null")
+	}
+
+	b, err := json.Marshal(it.members)
+	if err != nil {
+		return err
+	}
+	if err := validateJSONMemberNames(b, m.memberNameValidationMode, ""); err != nil {
+		return err
+	}
+
+	var d document
+	if err := json.Unmarshal(b, &d); err != nil {
+		return err
+	}
+	if err := d.validateTypeNames(m.memberNameValidationMode, ""); err != nil {
+		return err
+	}
+	d.hasMany = true
+	d.DataMany = it.primary
+
+	if d.isErrorDocument() {
+		if err := d.unmarshalOptionalFields(m); err != nil {
+			return err
+		}
+		return &DocumentError{Errors: d.Errors}
+	}
+
+	if m.checkUniqueness {
+		if ok := d.verifyResourceUniqueness(); !ok {
+			return ErrNonuniqueResource
+		}
+	}
+	if verifyLinkage {
+		if err := d.verifyFullLinkage(false); err != nil {
+			return err
+		}
+	}
+
+	return d.unmarshalOptionalFields(m)
+}
+
+// maxBytesReader is an io.Reader which fails with ErrDocumentTooLarge once a read goes past the
+// first n bytes of r, like http.MaxBytesReader.
+type maxBytesReader struct {
+	r   io.Reader
+	n   int64
+	err error
+}
+
+// Read implements the io.Reader interface.
+func (r *maxBytesReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	// reading one byte more than the remaining limit tells a read reaching the limit from one
+	// exceeding it, without a separate read past the limit
+	if int64(len(p))-1 > r.n {
+		p = p[:r.n+1]
+	}
+	n, err := r.r.Read(p)
+	if int64(n) <= r.n {
+		r.n -= int64(n)
+		r.err = err
+		return n, err
+	}
+
+	n = int(r.n)
+	r.n = 0
+	r.err = ErrDocumentTooLarge
+	return n, r.err
+}
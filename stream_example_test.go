@@ -0,0 +1,102 @@
+package jsonapi_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/DataDog/jsonapi"
+)
+
+func ExampleEncoder() {
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	a := Article{ID: "1", Title: "Hello World"}
+
+	enc := jsonapi.NewEncoder(os.Stdout, jsonapi.MarshalMeta(map[string]any{"foo": "bar"}))
+	if err := enc.Encode(&a); err != nil {
+		panic(err)
+	}
+
+	// Output: {"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"}},"meta":{"foo":"bar"}}
+}
+
+func ExampleEncoder_Stream() {
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	rows := make(chan Article, 2)
+	rows <- Article{ID: "1", Title: "Hello World"}
+	rows <- Article{ID: "2", Title: "Hello Again"}
+	close(rows)
+
+	sw := jsonapi.NewEncoder(os.Stdout).Stream()
+
+	var last string
+	for a := range rows {
+		if err := sw.Write(&a); err != nil {
+			panic(err)
+		}
+		last = a.ID
+	}
+
+	links := &jsonapi.Link{Next: "/articles?page[cursor]=" + last}
+	if err := sw.Close(jsonapi.MarshalLinks(links)); err != nil {
+		panic(err)
+	}
+
+	// Output: {"data":[{"id":"1","type":"articles","attributes":{"title":"Hello World"}},{"id":"2","type":"articles","attributes":{"title":"Hello Again"}}],"links":{"next":"/articles?page[cursor]=2"}}
+}
+
+func ExampleDecoder() {
+	body := `{"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"}}}`
+
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	dec := jsonapi.NewDecoder(strings.NewReader(body))
+	dec.SetMaxBytes(1 << 20)
+
+	var a Article
+	if err := dec.Decode(&a); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%+v", &a)
+	// Output: &{ID:1 Title:Hello World}
+}
+
+func ExampleDecoder_Next() {
+	body := `{"data":[{"id":"1","type":"articles","attributes":{"title":"Hello World"}},{"id":"2","type":"articles","attributes":{"title":"Hello Again"}}]}`
+
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	dec := jsonapi.NewDecoder(strings.NewReader(body))
+	for {
+		var a Article
+		err := dec.Next(&a)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			panic(err)
+		}
+		fmt.Printf("%+v\n", &a)
+	}
+
+	// Output:
+	// &{ID:1 Title:Hello World}
+	// &{ID:2 Title:Hello Again}
+}
@@ -0,0 +1,650 @@
+package jsonapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/jsonapi/internal/is"
+)
+
+func TestEncoder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       any
+		opts        []MarshalOption
+		expect      string
+		expectError error
+	}{
+		{
+			description: "*Article",
+			given:       &articleA,
+			expect:      articleABody,
+		}, {
+			description: "[]Article",
+			given:       articlesAB,
+			expect:      articlesABBody,
+		}, {
+			description: "[]Article (nonunique data)",
+			given:       articlesAA,
+			opts:        []MarshalOption{MarshallCheckUniqueness()},
+			expectError: ErrNonuniqueResource,
+		}, {
+			description: "Article with included author (not linked)",
+			given:       &articleA,
+			opts:        []MarshalOption{MarshalInclude(&authorA)},
+			expectError: &PartialLinkageError{[]string{"{Type: author, ID: 1}"}},
+		}, {
+			description: "Author with invalid attribute member name",
+			given:       &authorWithInvalidAttributeName,
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/data/attributes/na%me"},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			var buf bytes.Buffer
+			err := NewEncoder(&buf, tc.opts...).Encode(tc.given)
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				is.Equal(t, 0, buf.Len())
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, byte('\n'), buf.Bytes()[buf.Len()-1])
+			is.EqualJSON(t, tc.expect, buf.String())
+		})
+	}
+}
+
+func TestEncoderSetIndent(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetIndent("", "\t")
+
+	err := enc.Encode(&articleA)
+	is.MustNoError(t, err)
+	is.Equal(t, "{\n\t\"data\": {\n\t\t\"id\": \"1\",\n\t\t\"type\": \"articles\",\n\t\t\"attributes\": {\n\t\t\t\"title\": \"A\"\n\t\t}\n\t}\n}\n", buf.String())
+}
+
+func TestDecoder(t *testing.T) {
+	t.Parallel()
+
+	articleAUnknownAttributeBody := `{"data":{"type":"articles","id":"1","attributes":{"title":"A","unknown":"B"}}}`
+
+	tests := []struct {
+		description string
+		given       string
+		do          func(dec *Decoder) (any, error)
+		expect      any
+		expectError error
+	}{
+		{
+			description: "*Article",
+			given:       articleABody,
+			do: func(dec *Decoder) (any, error) {
+				var a Article
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expect: &articleA,
+		}, {
+			description: "[]*ArticleRelated complex relationships with include",
+			given:       articlesRelatedComplexBody,
+			do: func(dec *Decoder) (any, error) {
+				var a []*ArticleRelated
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expect: &articlesRelatedComplex,
+		}, {
+			description: "*Article with included author (not linked)",
+			given:       articleWithIncludeOnlyBody,
+			do: func(dec *Decoder) (any, error) {
+				var a Article
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expectError: &PartialLinkageError{[]string{"{Type: author, ID: 1}"}},
+		}, {
+			description: "Author with invalid attribute member name",
+			given:       authorWithInvalidAttributeNameBody,
+			do: func(dec *Decoder) (any, error) {
+				var a AuthorWithInvalidAttributeName
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/data/attributes/na%me"},
+		}, {
+			description: "*Article unknown attribute",
+			given:       articleAUnknownAttributeBody,
+			do: func(dec *Decoder) (any, error) {
+				var a Article
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expect: &articleA,
+		}, {
+			description: "*Article unknown attribute (DisallowUnknownFields)",
+			given:       articleAUnknownAttributeBody,
+			do: func(dec *Decoder) (any, error) {
+				dec.DisallowUnknownFields()
+				var a Article
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expectError: errors.New(`json: unknown field "unknown"`),
+		}, {
+			description: "*Article (SetMaxBytes)",
+			given:       articleABody,
+			do: func(dec *Decoder) (any, error) {
+				dec.SetMaxBytes(int64(len(articleABody)))
+				var a Article
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expect: &articleA,
+		}, {
+			description: "*Article (SetMaxBytes exceeded)",
+			given:       articleABody,
+			do: func(dec *Decoder) (any, error) {
+				dec.SetMaxBytes(int64(len(articleABody) - 1))
+				var a Article
+				err := dec.Decode(&a)
+				return &a, err
+			},
+			expectError: ErrDocumentTooLarge,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := tc.do(NewDecoder(strings.NewReader(tc.given)))
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+func TestDecoderStream(t *testing.T) {
+	t.Parallel()
+
+	var m map[string]any
+	dec := NewDecoder(strings.NewReader(articleAToplevelMetaBody+"\n"+articlesABBody), UnmarshalMeta(&m))
+
+	var a Article
+	err := dec.Decode(&a)
+	is.MustNoError(t, err)
+	is.Equal(t, articleA, a)
+	is.Equal(t, map[string]any{"foo": "bar"}, m)
+
+	var as []Article
+	err = dec.Decode(&as)
+	is.MustNoError(t, err)
+	is.Equal(t, articlesAB, as)
+}
+
+func TestDecoderNext(t *testing.T) {
+	t.Parallel()
+
+	articlesRelatedAuthorIncludedFirstBody := `{"included":[{"id":"1","type":"author","attributes":{"name":"A"}}],"data":[{"id":"1","type":"articles","attributes":{"title":"A"},"relationships":{"author":{"data":{"id":"1","type":"author"}}}},{"id":"2","type":"articles","attributes":{"title":"B"}}],"meta":{"foo":"bar"}}`
+
+	tests := []struct {
+		description string
+		given       string
+		setup       func(dec *Decoder)
+		opts        []UnmarshalOption
+		expect      []*ArticleRelated
+		expectError error
+	}{
+		{
+			description: "many",
+			given:       articlesABBody,
+			expect:      []*ArticleRelated{{ID: "1", Title: "A"}, {ID: "2", Title: "B"}},
+		}, {
+			description: "one",
+			given:       articleABody,
+			expect:      []*ArticleRelated{{ID: "1", Title: "A"}},
+		}, {
+			description: "null",
+			given:       nullDataBody,
+			expect:      nil,
+		}, {
+			description: "empty",
+			given:       emptyManyBody,
+			expect:      nil,
+		}, {
+			description: "empty object",
+			given:       emptySingleBody,
+			expectError: ErrEmptyDataObject,
+		}, {
+			description: "included before data",
+			given:       articlesRelatedAuthorIncludedFirstBody,
+			expect:      []*ArticleRelated{{ID: "1", Title: "A", Author: &Author{ID: "1"}}, {ID: "2", Title: "B"}},
+		}, {
+			description: "included not linked",
+			given:       articleWithIncludeOnlyBody,
+			expect:      []*ArticleRelated{{ID: "1", Title: "A"}},
+			expectError: &PartialLinkageError{[]string{"{Type: author, ID: 1}"}},
+		}, {
+			description: "included not linked (DisableFullLinkageCheck)",
+			given:       articleWithIncludeOnlyBody,
+			setup:       func(dec *Decoder) { dec.DisableFullLinkageCheck() },
+			expect:      []*ArticleRelated{{ID: "1", Title: "A"}},
+		}, {
+			description: "nonunique data",
+			given:       articlesABNonuniqueData,
+			opts:        []UnmarshalOption{UnmarshalCheckUniqueness()},
+			expect:      []*ArticleRelated{{ID: "1", Title: "A"}, {ID: "1", Title: "B"}},
+			expectError: ErrNonuniqueResource,
+		}, {
+			description: "invalid type",
+			given:       articleAInvalidTypeBody,
+			expectError: &TypeError{Actual: "not-articles", Expected: []string{"articles"}},
+		}, {
+			description: "invalid type name",
+			given:       `{"data":[{"id":"1","type":"articles"},{"id":"2","type":"art%icles"}]}`,
+			expect:      []*ArticleRelated{{ID: "1"}},
+			expectError: &MemberNameValidationError{MemberName: "art%icles", Path: "/data/1/type"},
+		}, {
+			description: "errors",
+			given:       errorsSimpleStructBody,
+			expectError: &DocumentError{Errors: []*Error{&errorsSimpleStruct}},
+		}, {
+			description: "empty errors",
+			given:       `{"errors":[]}`,
+			expectError: &DocumentError{Errors: []*Error{}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			dec := NewDecoder(strings.NewReader(tc.given), tc.opts...)
+			if tc.setup != nil {
+				tc.setup(dec)
+			}
+
+			var (
+				actual []*ArticleRelated
+				err    error
+			)
+			for {
+				var a ArticleRelated
+				if err = dec.Next(&a); err != nil {
+					break
+				}
+				actual = append(actual, &a)
+			}
+
+			is.Equal(t, tc.expect, actual)
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestDecoderNextOptionalFields(t *testing.T) {
+	t.Parallel()
+
+	body := `{"meta":{"foo":"bar"},"data":[{"id":"1","type":"articles","attributes":{"title":"A"}}],"links":{"next":"http://example.com/articles?page[cursor]=1"}}`
+
+	var (
+		m map[string]any
+		l Link
+	)
+	dec := NewDecoder(strings.NewReader(body+body), UnmarshalMeta(&m), UnmarshalLinks(&l))
+
+	for i := 0; i < 2; i++ {
+		var a Article
+		err := dec.Next(&a)
+		is.MustNoError(t, err)
+		is.Equal(t, articleA, a)
+
+		err = dec.Decode(&a)
+		is.EqualError(t, ErrDecoderIterating, err)
+
+		err = dec.Next(&a)
+		is.Equal(t, io.EOF, err)
+		is.Equal(t, map[string]any{"foo": "bar"}, m)
+		is.Equal(t, Link{Next: "http://example.com/articles?page[cursor]=1"}, l)
+	}
+}
+
+func TestDecoderNextRetainedLinkage(t *testing.T) {
+	t.Parallel()
+
+	body := `{"data":[{"id":"1","type":"articles","attributes":{"title":"A"},"relationships":{"author":{"data":{"id":"1","type":"author"}}}}]}`
+
+	tests := []struct {
+		description  string
+		setup        func(dec *Decoder)
+		opts         []UnmarshalOption
+		expectLinked bool
+		expectKept   bool
+	}{
+		{
+			description:  "full-linkage check",
+			expectLinked: true,
+			expectKept:   true,
+		}, {
+			description: "no checks",
+			setup:       func(dec *Decoder) { dec.DisableFullLinkageCheck() },
+		}, {
+			description: "uniqueness check only",
+			setup:       func(dec *Decoder) { dec.DisableFullLinkageCheck() },
+			opts:        []UnmarshalOption{UnmarshalCheckUniqueness()},
+			expectKept:  true,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			dec := NewDecoder(strings.NewReader(body), tc.opts...)
+			if tc.setup != nil {
+				tc.setup(dec)
+			}
+
+			var a ArticleRelated
+			is.MustNoError(t, dec.Next(&a))
+			is.Equal(t, tc.expectKept, len(dec.it.primary) == 1)
+			if tc.expectKept {
+				is.Equal(t, "1", dec.it.primary[0].ID)
+				is.Equal(t, tc.expectLinked, dec.it.primary[0].Relationships != nil)
+			}
+			is.Equal(t, io.EOF, dec.Next(&a))
+		})
+	}
+}
+
+// stalledReader returns its data and then fails, like a connection whose peer has sent the whole
+// body without closing it.
+type stalledReader struct {
+	data []byte
+}
+
+var errStalled = errors.New("read past the sent data")
+
+func (r *stalledReader) Read(p []byte) (int, error) {
+	if len(r.data) == 0 {
+		return 0, errStalled
+	}
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestMaxBytesReader(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		limit       int64
+		expect      string
+		expectError error
+	}{
+		{
+			description: "below the limit",
+			given:       "abc",
+			limit:       4,
+			expect:      "abc",
+		}, {
+			description: "exactly the limit",
+			given:       "abc",
+			limit:       3,
+			expect:      "abc",
+		}, {
+			description: "over the limit",
+			given:       "abcd",
+			limit:       3,
+			expect:      "abc",
+			expectError: ErrDocumentTooLarge,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			// a single read must neither go past the data nor miss that the limit is exceeded
+			r := &maxBytesReader{r: &stalledReader{data: []byte(tc.given)}, n: tc.limit}
+			p := make([]byte, 16)
+			n, err := r.Read(p)
+			is.Equal(t, tc.expect, string(p[:n]))
+			is.Equal(t, tc.expectError, err)
+		})
+	}
+}
+
+func TestStreamWriter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       []any
+		encoderOpts []MarshalOption
+		closeOpts   []MarshalOption
+		expect      string
+		expectError error
+	}{
+		{
+			description: "empty",
+			given:       nil,
+			expect:      emptyManyBody,
+		}, {
+			description: "[]Article",
+			given:       []any{articleA, &articleB},
+			expect:      articlesABBody,
+		}, {
+			description: "[]*ArticleRelated complex relationships with include",
+			given: []any{
+				articlesRelatedComplex[0],
+				articlesRelatedComplex[1],
+				articlesRelatedComplex[2],
+				articlesRelatedComplex[3],
+			},
+			closeOpts:   articlesRelatedComplexMarshalOptions,
+			expectError: ErrStreamIncludes,
+		}, {
+			description: "[]*ArticleRelated complex relationships with include (encoder options)",
+			given: []any{
+				articlesRelatedComplex[0],
+				articlesRelatedComplex[1],
+				articlesRelatedComplex[2],
+				articlesRelatedComplex[3],
+			},
+			encoderOpts: articlesRelatedComplexMarshalOptions,
+			expect:      articlesRelatedComplexBody,
+		}, {
+			description: "Article with include paths given to Close",
+			given:       []any{&articleRelatedCommentsNested},
+			closeOpts:   []MarshalOption{MarshalIncludePaths("comments")},
+			expectError: ErrStreamIncludes,
+		}, {
+			description: "[]*ArticleRelated with include paths",
+			given:       []any{&articleRelatedCommentsNested, &articleRelatedCommentsNested},
+			encoderOpts: []MarshalOption{MarshalIncludePaths("comments.author")},
+			expect:      `{"data":[{"id":"1","type":"articles","attributes":{"title":"A"},"relationships":{"comments":{"data":[{"id":"1","type":"comments"}],"links":{"self":"http://example.com/articles/1/relationships/comments","related":"http://example.com/articles/1/comments"}}}},{"id":"1","type":"articles","attributes":{"title":"A"},"relationships":{"comments":{"data":[{"id":"1","type":"comments"}],"links":{"self":"http://example.com/articles/1/relationships/comments","related":"http://example.com/articles/1/comments"}}}}],"included":[{"id":"1","type":"comments","attributes":{"body":"A"},"relationships":{"author":{"data":{"id":"1","type":"author"},"links":{"self":"http://example.com/comments/1/relationships/author","related":"http://example.com/comments/1/author"}}}},{"id":"1","type":"author","attributes":{"name":"A"}}]}`,
+		}, {
+			description: "Article with unknown include path",
+			given:       []any{&articleRelated},
+			encoderOpts: []MarshalOption{MarshalIncludePaths("editor")},
+			expectError: &Error{Title: "Invalid include path", Detail: `"editor" is not a relationship of "articles" in include path "editor"`},
+		}, {
+			description: "Article with included author (not linked)",
+			given:       []any{articleA},
+			encoderOpts: []MarshalOption{MarshalInclude(&authorA)},
+			expectError: &PartialLinkageError{[]string{"{Type: author, ID: 1}"}},
+		}, {
+			description: "[]Article (nonunique data)",
+			given:       []any{articleA, articleA},
+			encoderOpts: []MarshalOption{MarshallCheckUniqueness()},
+			expectError: ErrNonuniqueResource,
+		}, {
+			description: "Author with invalid attribute member name",
+			given:       []any{authorWithInvalidAttributeName},
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/data/0/attributes/na%me"},
+		}, {
+			description: "string",
+			given:       []any{"a"},
+			expectError: &TypeError{Actual: "string", Expected: []string{"struct"}},
+		}, {
+			description: "nil",
+			given:       []any{nil},
+			expectError: &TypeError{Actual: "nil", Expected: []string{"struct"}},
+		}, {
+			description: "fields",
+			given:       []any{articleA},
+			encoderOpts: []MarshalOption{MarshalFields(map[string][]string{"fields[articles]": {"id"}})},
+			expect:      `{"data":[{"type":"articles","id":"1"}]}`,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			var buf bytes.Buffer
+			sw := NewEncoder(&buf, tc.encoderOpts...).Stream()
+
+			var err error
+			for _, v := range tc.given {
+				if err = sw.Write(v); err != nil {
+					break
+				}
+			}
+			if err == nil {
+				err = sw.Close(tc.closeOpts...)
+			}
+
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				is.EqualError(t, tc.expectError, sw.Close())
+				return
+			}
+			is.MustNoError(t, err)
+			is.EqualJSON(t, tc.expect, buf.String())
+			is.EqualError(t, ErrStreamClosed, sw.Write(articleA))
+		})
+	}
+}
+
+func TestStreamWriterRetainedState(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description  string
+		opts         []MarshalOption
+		expectSeen   int
+		expectLinked int
+	}{
+		{
+			description: "no checks",
+		}, {
+			description: "uniqueness check",
+			opts:        []MarshalOption{MarshallCheckUniqueness()},
+			expectSeen:  3,
+		}, {
+			description:  "included resource",
+			opts:         []MarshalOption{MarshalInclude(&authorA)},
+			expectLinked: 1,
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			var buf bytes.Buffer
+			sw := NewEncoder(&buf, tc.opts...).Stream()
+			for _, id := range []string{"1", "2", "3"} {
+				is.MustNoError(t, sw.Write(&ArticleRelated{ID: id, Title: "A", Author: &Author{ID: "1"}}))
+			}
+
+			// the linkage of the written resources is only kept for the included resources
+			is.Equal(t, tc.expectSeen, len(sw.seen))
+			is.Equal(t, tc.expectLinked, len(sw.linked))
+			is.MustNoError(t, sw.Close())
+		})
+	}
+}
+
+func TestEncoderEncodeEach(t *testing.T) {
+	t.Parallel()
+
+	ch := make(chan *Article, len(articlesABPtr))
+	for _, a := range articlesABPtr {
+		ch <- a
+	}
+	close(ch)
+
+	var (
+		buf  bytes.Buffer
+		link Link
+	)
+	err := NewEncoder(&buf).EncodeEach(func() (any, error) {
+		a, ok := <-ch
+		if !ok {
+			return nil, io.EOF
+		}
+		link.Next = "http://example.com/articles?page[cursor]=" + a.ID
+		return a, nil
+	}, MarshalLinks(&link))
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":[{"type":"articles","id":"1","attributes":{"title":"A"}},{"type":"articles","id":"2","attributes":{"title":"B"}}],"links":{"next":"http://example.com/articles?page[cursor]=2"}}`, buf.String())
+
+	// included resources given to EncodeEach are linked as the resources are written
+	buf.Reset()
+	done := false
+	err = NewEncoder(&buf).EncodeEach(func() (any, error) {
+		if done {
+			return nil, io.EOF
+		}
+		done = true
+		return &ArticleRelated{ID: "1", Title: "A", Author: &Author{ID: "1"}}, nil
+	}, MarshalInclude(&authorA))
+	is.MustNoError(t, err)
+	is.EqualJSON(t, `{"data":[{"type":"articles","id":"1","attributes":{"title":"A"},"relationships":{"author":{"data":{"id":"1","type":"author"},"links":{"self":"http://example.com/articles/1/relationships/author","related":"http://example.com/articles/1/author"}}}}],"included":[{"id":"1","type":"author","attributes":{"name":"A"}}]}`, buf.String())
+
+	expectErr := errors.New("database error")
+	err = NewEncoder(&buf).EncodeEach(func() (any, error) {
+		return nil, expectErr
+	})
+	is.Equal(t, expectErr, err)
+}
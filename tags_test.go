@@ -6,133 +6,73 @@ import (
 	"testing"
 
 	"github.com/DataDog/jsonapi/internal/is"
+	"github.com/DataDog/jsonapi/internal/schema"
 )
 
-func TestParseJSONTag(t *testing.T) {
+func TestCachedTypeFields(t *testing.T) {
 	t.Parallel()
 
-	tests := []struct {
-		description string
-		fieldName   string
-		given       any
-		expect      string
-		expectOK    bool
-		expectOmit  bool
-	}{
-		{
-			description: "valid",
-			fieldName:   "Foo",
-			given: struct {
-				Foo string `json:"foo"`
-			}{},
-			expect:     "foo",
-			expectOK:   true,
-			expectOmit: false,
-		}, {
-			description: "valid multiple values",
-			fieldName:   "Foo",
-			given: struct {
-				Foo string `json:"foo,omitempty"`
-			}{},
-			expect:     "foo",
-			expectOK:   true,
-			expectOmit: true,
-		}, {
-			description: "no tag, uses field name",
-			fieldName:   "Foo",
-			given: struct {
-				Foo string
-			}{},
-			expect:     "Foo",
-			expectOK:   true,
-			expectOmit: false,
-		}, {
-			description: "unexported field",
-			fieldName:   "foo",
-			given: struct {
-				foo string
-			}{},
-			expect:     "",
-			expectOK:   false,
-			expectOmit: false,
-		},
+	type fieldSummary struct {
+		Index      []int
+		Name       string
+		Directive  schema.Directive
+		MemberName string
 	}
 
-	for i, tc := range tests {
-		tc := tc
-		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
-			t.Parallel()
-			t.Log(tc.description)
-
-			sf, ok := reflect.TypeOf(tc.given).FieldByName(tc.fieldName)
-			is.Equal(t, true, ok)
-
-			tag, ok, omit := parseJSONTag(sf)
-			is.Equal(t, tc.expectOK, ok)
-			is.Equal(t, tc.expect, tag)
-			is.Equal(t, tc.expectOmit, omit)
-		})
-	}
-}
-
-func TestParseJSONAPITag(t *testing.T) {
-	t.Parallel()
-
 	tests := []struct {
 		description string
 		given       any
-		expect      *tag
+		expect      []fieldSummary
 		expectError error
 	}{
 		{
-			description: "valid jsonapi, attribute",
-			given: struct {
-				Foo string `jsonapi:"attribute"`
-			}{},
-			expect: &tag{directive: attribute},
-		}, {
-			description: "valid jsonapi, relationship",
-			given: struct {
-				Foo string `jsonapi:"relationship"`
-			}{},
-			expect: &tag{directive: relationship},
+			description: "Article",
+			given:       Article{},
+			expect: []fieldSummary{
+				{Index: []int{0}, Name: "ID", Directive: schema.Primary, MemberName: "ID"},
+				{Index: []int{1}, Name: "Title", Directive: schema.Attribute, MemberName: "title"},
+			},
 		}, {
-			description: "valid jsonapi, primary",
-			given: struct {
-				Foo string `jsonapi:"primary,foo"`
-			}{},
-			expect: &tag{directive: primary, resourceType: "foo"},
+			description: "ArticleEmbedded",
+			given:       ArticleEmbedded{},
+			expect: []fieldSummary{
+				{Index: []int{0, 0}, Name: "LastModified", Directive: schema.Attribute, MemberName: "lastModified"},
+				{Index: []int{1}, Name: "ID", Directive: schema.Primary, MemberName: "ID"},
+				{Index: []int{2}, Name: "Title", Directive: schema.Attribute, MemberName: "title"},
+			},
 		}, {
-			description: "valid jsonapi, primary, omitempty",
-			given: struct {
-				Foo string `jsonapi:"primary,foo,omitempty"`
-			}{},
-			expect: &tag{directive: primary, resourceType: "foo", omitEmpty: true},
+			description: "ArticleEmbeddedPointer",
+			given:       ArticleEmbeddedPointer{},
+			expect: []fieldSummary{
+				{Index: []int{0, 0}, Name: "LastModified", Directive: schema.Attribute, MemberName: "lastModified"},
+				{Index: []int{1}, Name: "ID", Directive: schema.Primary, MemberName: "ID"},
+				{Index: []int{2}, Name: "Title", Directive: schema.Attribute, MemberName: "title"},
+			},
 		}, {
-			description: "no struct tags",
-			given:       struct{ Foo string }{},
-			expect:      nil,
+			description: "CommentEmbedded",
+			given:       CommentEmbedded{},
+			expect: []fieldSummary{
+				{Index: []int{0}, Name: "ID", Directive: schema.Primary, MemberName: "ID"},
+				{Index: []int{1, 0}, Name: "Body", Directive: schema.Attribute, MemberName: "body"},
+				{Index: []int{1, 1}, Name: "Archived", Directive: schema.Attribute, MemberName: "archived"},
+				{Index: []int{1, 2}, Name: "Author", Directive: schema.Relationship, MemberName: "author"},
+			},
 		}, {
-			description: "invalid jsonapi tag (missing value)",
-			given: struct {
-				Foo string `jsonapi:"primary"`
-			}{},
-			expect: nil,
-			expectError: &TagError{
-				TagName: "jsonapi",
-				Field:   "Foo",
-				Reason:  "missing type in primary directive",
+			description: "Node (recursive embedding)",
+			given:       Node{},
+			expect: []fieldSummary{
+				{Index: []int{0}, Name: "ID", Directive: schema.Primary, MemberName: "ID"},
+				{Index: []int{1}, Name: "Label", Directive: schema.Attribute, MemberName: "label"},
 			},
 		}, {
-			description: "invalid jsonapi tag (invalid directive)",
+			description: "invalid jsonapi tag",
 			given: struct {
-				Foo string `jsonapi:"invalid,foo"`
+				ID string `jsonapi:"primary"`
 			}{},
-			expect: nil,
 			expectError: &TagError{
 				TagName: "jsonapi",
-				Field:   "Foo",
-				Reason:  "invalid directive",
+				Field:   "ID",
+				Reason:  "missing type in primary directive",
 			},
 		},
 	}
@@ -143,12 +83,17 @@ func TestParseJSONAPITag(t *testing.T) {
 			t.Parallel()
 			t.Log(tc.description)
 
-			sf, ok := reflect.TypeOf(tc.given).FieldByName("Foo")
-			is.Equal(t, true, ok)
+			// the second lookup is served from the cache and must give the same result
+			for n := 0; n < 2; n++ {
+				fields, err := schema.CachedTypeFields(reflect.TypeOf(tc.given))
+				is.MustEqualError(t, tc.expectError, err)
 
-			tag, err := parseJSONAPITag(sf)
-			is.MustEqualError(t, tc.expectError, err)
-			is.Equal(t, tc.expect, tag)
+				var actual []fieldSummary
+				for _, f := range fields {
+					actual = append(actual, fieldSummary{f.Index, f.Name, f.Tag.Directive, f.MemberName})
+				}
+				is.Equal(t, tc.expect, actual)
+			}
 		})
 	}
 }
@@ -1,13 +1,20 @@
 package jsonapi
 
 import (
+	"bytes"
 	"encoding"
 	"encoding/json"
 	"reflect"
+
+	"github.com/DataDog/jsonapi/internal/schema"
 )
 
-// Unmarshaler is configured internally via UnmarshalOption's passed to Unmarshal.
+// Unmarshaler is configured via UnmarshalOption's, either passed to Unmarshal or to NewUnmarshaler.
 // It's used to configure the Unmarshaling by decoding optional fields like Meta.
+//
+// An Unmarshaler is safe for concurrent use by multiple goroutines. Options which decode into a
+// given value, like UnmarshalMeta and UnmarshalLinks, should be passed to each call instead of
+// NewUnmarshaler so that concurrent calls don't share that value.
 type Unmarshaler struct {
 	unmarshalMeta            bool
 	unmarshalLinks           bool
@@ -15,6 +22,12 @@ type Unmarshaler struct {
 	meta                     any
 	links                    *Link
 	memberNameValidationMode MemberNameValidationMode
+	disallowUnknownFields    bool
+	types                    registry
+	included                 any
+	preserveIdentity         bool
+	identities               *identities
+	merge                    bool
 }
 
 // UnmarshalOption allows for configuration of Unmarshaling.
@@ -36,6 +49,17 @@ func UnmarshalLinks(link *Link) UnmarshalOption {
 	}
 }
 
+// UnmarshalIncluded decodes the included resources of a compound document into the given value,
+// in addition to the relationship fields they are linked from. It must be a pointer to a slice,
+// like the ones primary data is unmarshaled into, or a pointer to a map of such slices keyed by
+// resource type (e.g. *map[string][]any), which is useful with types registered by RegisterType.
+// The given value is left untouched if the document has no included resources.
+func UnmarshalIncluded(included any) UnmarshalOption {
+	return func(m *Unmarshaler) {
+		m.included = included
+	}
+}
+
 // UnmarshalCheckUniqueness enables checking for unique resources during unmarshaling.
 func UnmarshalCheckUniqueness() UnmarshalOption {
 	return func(m *Unmarshaler) {
@@ -56,12 +80,73 @@ func (m *Unmarshaler) relationshipUnmarshaler() *Unmarshaler {
 	rm := new(Unmarshaler)
 
 	rm.memberNameValidationMode = m.memberNameValidationMode
+	rm.disallowUnknownFields = m.disallowUnknownFields
+	rm.types = m.types
+	rm.identities = m.identities
+	rm.merge = m.merge
 	return rm
 }
 
 // Unmarshal parses the json:api encoded data and stores the result in the value pointed to by v.
 // If v is nil or not a pointer, Unmarshal returns an error.
-func Unmarshal(data []byte, v any, opts ...UnmarshalOption) (err error) {
+//
+// Resource objects are unmarshaled into structs, or into interfaces using the types registered for
+// their resource types (see RegisterType). Heterogeneous primary data, like search results mixing
+// articles and videos, can thus be unmarshaled into a []any or a slice of a common interface.
+//
+// If the data is an error document, the error objects are stored in v when it is a *[]*Error or
+// *Error, otherwise a *DocumentError holding them is returned.
+func Unmarshal(data []byte, v any, opts ...UnmarshalOption) error {
+	return NewUnmarshaler(opts...).unmarshal(data, v)
+}
+
+// NewUnmarshaler returns an Unmarshaler configured with the given options, meant to be created
+// once and reused for every document sharing the same configuration (e.g. name validation).
+func NewUnmarshaler(opts ...UnmarshalOption) *Unmarshaler {
+	m := new(Unmarshaler)
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// with returns m when no options are given, otherwise a copy of m with the given options applied.
+func (m *Unmarshaler) with(opts []UnmarshalOption) *Unmarshaler {
+	if len(opts) == 0 {
+		return m
+	}
+
+	cm := *m
+	for _, opt := range opts {
+		opt(&cm)
+	}
+	return &cm
+}
+
+// Unmarshal parses the json:api encoded data and stores the result in the value pointed to by v
+// like the package-level Unmarshal, using the options m was created with. The given options are
+// applied on top of those for this call only.
+func (m *Unmarshaler) Unmarshal(data []byte, v any, opts ...UnmarshalOption) error {
+	return m.with(opts).unmarshal(data, v)
+}
+
+// unmarshal parses the json:api encoded data into v using the configuration of m.
+func (m *Unmarshaler) unmarshal(data []byte, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &TypeError{Actual: rv.Kind().String(), Expected: []string{"non-nil pointer"}}
+	}
+
+	d, err := m.parseDocument(data)
+	if err != nil {
+		return err
+	}
+
+	return m.unmarshalDocument(d, v)
+}
+
+// unmarshalDocument unmarshals the parsed document d into v using the configuration of m.
+func (m *Unmarshaler) unmarshalDocument(d *document, v any) (err error) {
 	defer func() {
 		// because we make use of reflect we must recover any panics
 		if rvr := recover(); rvr != nil {
@@ -70,29 +155,31 @@ func Unmarshal(data []byte, v any, opts ...UnmarshalOption) (err error) {
 		}
 	}()
 
-	m := new(Unmarshaler)
-	for _, opt := range opts {
-		opt(m)
+	if m.preserveIdentity {
+		// identities are tracked per document, since m may be shared
+		cm := *m
+		cm.identities = newIdentities(d)
+		m = &cm
 	}
 
-	rv := reflect.ValueOf(v)
-	if rv.Kind() != reflect.Pointer || rv.IsNil() {
-		err = &TypeError{Actual: rv.Kind().String(), Expected: []string{"non-nil pointer"}}
-		return
-	}
+	return d.unmarshal(v, m)
+}
 
+// parseDocument parses the json:api encoded data into a document and validates its member names.
+func (m *Unmarshaler) parseDocument(data []byte) (*document, error) {
 	var d document
-	if err = json.Unmarshal(data, &d); err != nil {
-		return
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, err
 	}
 
-	if err = validateJSONMemberNames(data, m.memberNameValidationMode); err != nil {
-		return
+	if err := validateJSONMemberNames(data, m.memberNameValidationMode, ""); err != nil {
+		return nil, err
+	}
+	if err := d.validateTypeNames(m.memberNameValidationMode, ""); err != nil {
+		return nil, err
 	}
 
-	err = d.unmarshal(v, m)
-
-	return
+	return &d, nil
 }
 
 func (d *document) unmarshal(v any, m *Unmarshaler) (err error) {
@@ -106,6 +193,15 @@ func (d *document) unmarshal(v any, m *Unmarshaler) (err error) {
 		return
 	}
 
+	if d.isErrorDocument() {
+		// errors and data cannot both exist in a document, so the optional fields are decoded
+		// before the errors are either stored in v or returned
+		if err = d.unmarshalOptionalFields(m); err != nil {
+			return
+		}
+		return unmarshalErrors(d.Errors, v)
+	}
+
 	if d.hasMany {
 		err = unmarshalResourceObjects(d.DataMany, v, m)
 		if err != nil {
@@ -116,9 +212,6 @@ func (d *document) unmarshal(v any, m *Unmarshaler) (err error) {
 		if err != nil {
 			return
 		}
-	} else if d.Errors != nil {
-		// TODO(#36): Support unmarshaling of errors
-		return ErrErrorUnmarshalingNotImplemented
 	}
 
 	err = d.unmarshalOptionalFields(m)
@@ -126,20 +219,47 @@ func (d *document) unmarshal(v any, m *Unmarshaler) (err error) {
 	return
 }
 
+// unmarshalErrors stores the given error objects in v if it is a *[]*Error, *[]Error, **Error or
+// *Error. Only the first error object is kept for single error targets. For any other type of v,
+// or if there are no error objects, a *DocumentError holding the error objects is returned.
+func unmarshalErrors(errs []*Error, v any) error {
+	if len(errs) == 0 {
+		// an empty errors member still makes an error document
+		return &DocumentError{Errors: errs}
+	}
+
+	switch t := v.(type) {
+	case *[]*Error:
+		*t = errs
+	case *[]Error:
+		*t = make([]Error, len(errs))
+		for i, e := range errs {
+			(*t)[i] = *e
+		}
+	case **Error:
+		*t = errs[0]
+	case *Error:
+		*t = *errs[0]
+	default:
+		return &DocumentError{Errors: errs}
+	}
+	return nil
+}
+
 func (d *document) unmarshalOptionalFields(m *Unmarshaler) error {
 	if m == nil {
 		// this is possible during recursive document unmarshaling
 		return nil
 	}
 	if m.unmarshalMeta {
-		b, err := json.Marshal(d.Meta)
+		b, err := rawMeta(d.Meta)
 		if err != nil {
 			return err
 		}
 		if err := json.Unmarshal(b, m.meta); err != nil {
 			return err
 		}
-		if err := validateJSONMemberNames(b, m.memberNameValidationMode); err != nil {
+		if err := validateJSONMemberNames(b, m.memberNameValidationMode, "/meta"); err != nil {
 			return err
 		}
 	}
@@ -148,11 +268,56 @@ func (d *document) unmarshalOptionalFields(m *Unmarshaler) error {
 			*m.links = *d.Links
 		}
 	}
+	if m.included != nil && len(d.Included) > 0 {
+		if err := unmarshalIncluded(d.Included, m.included, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unmarshalIncluded unmarshals the included resource objects into v, a pointer to a slice or to a
+// map of slices keyed by resource type.
+func unmarshalIncluded(ros []*resourceObject, v any, m *Unmarshaler) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &TypeError{Actual: rv.Kind().String(), Expected: []string{"non-nil pointer"}}
+	}
+
+	mt := rv.Elem().Type()
+	if mt.Kind() != reflect.Map {
+		return unmarshalResourceObjects(ros, v, m)
+	}
+	if mt.Key().Kind() != reflect.String || mt.Elem().Kind() != reflect.Slice {
+		return &TypeError{Actual: mt.String(), Expected: []string{"slice", "map[string]slice"}}
+	}
+
+	// group the resource objects by type, keeping their order
+	var resourceTypes []string
+	byType := make(map[string][]*resourceObject)
+	for _, ro := range ros {
+		if _, ok := byType[ro.Type]; !ok {
+			resourceTypes = append(resourceTypes, ro.Type)
+		}
+		byType[ro.Type] = append(byType[ro.Type], ro)
+	}
+
+	if rv.Elem().IsNil() {
+		rv.Elem().Set(reflect.MakeMapWithSize(mt, len(resourceTypes)))
+	}
+	for _, resourceType := range resourceTypes {
+		resources := reflect.New(mt.Elem())
+		if err := unmarshalResourceObjects(byType[resourceType], resources.Interface(), m); err != nil {
+			return err
+		}
+		rv.Elem().SetMapIndex(reflect.ValueOf(resourceType).Convert(mt.Key()), resources.Elem())
+	}
+
 	return nil
 }
 
 func unmarshalResourceObjects(ros []*resourceObject, v any, m *Unmarshaler) error {
-	outType := derefType(reflect.TypeOf(v))
+	outType := schema.DerefType(reflect.TypeOf(v))
 	outValue := derefValue(reflect.ValueOf(v))
 
 	// first, it must be a slice since we'll be parsing multiple resource objects
@@ -166,9 +331,30 @@ func unmarshalResourceObjects(ros []*resourceObject, v any, m *Unmarshaler) erro
 		outValue = reflect.MakeSlice(outType, 0, 0)
 	}
 
+	// when merging, the slice is replaced and its elements are reused for the same resources
+	var existing map[string]reflect.Value
+	if m.merge {
+		existing = existingResources(outValue)
+		outValue = reflect.MakeSlice(outType, 0, len(ros))
+	}
+
 	for _, ro := range ros {
+		// reuse the value the resource was already unmarshaled into, if identities are preserved
+		if p, ok := m.identities.lookup(ro, outType.Elem()); ok && outType.Elem().Kind() == reflect.Pointer {
+			if err := ro.unmarshal(p.Interface(), m); err != nil {
+				return err
+			}
+			outValue = reflect.Append(outValue, p)
+			continue
+		}
+
 		// unmarshal the resource object into an empty value of the slices element type
-		outElem := reflect.New(derefType(outType.Elem())).Interface()
+		outElem := reflect.New(schema.DerefType(outType.Elem())).Interface()
+		if ev, ok := existing[ro.getIdentifier()]; ok {
+			if target, ok := mergeTarget(ev, outType.Elem()); ok {
+				outElem = target
+			}
+		}
 		if err := ro.unmarshal(outElem, m); err != nil {
 			return err
 		}
@@ -190,135 +376,112 @@ func unmarshalResourceObjects(ros []*resourceObject, v any, m *Unmarshaler) erro
 	return nil
 }
 
+// unmarshalResourceObject unmarshals a single resource object into v, recovering from any panics.
+func (m *Unmarshaler) unmarshalResourceObject(ro *resourceObject, v any) (err error) {
+	defer func() {
+		// because we make use of reflect we must recover any panics
+		if rvr := recover(); rvr != nil {
+			err = recoverError(rvr)
+			return
+		}
+	}()
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &TypeError{Actual: rv.Kind().String(), Expected: []string{"non-nil pointer"}}
+	}
+
+	return ro.unmarshal(v, m)
+}
+
 func (ro *resourceObject) unmarshal(v any, m *Unmarshaler) error {
-	// first, it must be a struct since we'll be parsing the jsonapi struct tags
+	// interfaces are set to a value of the type registered for the resource type
 	vt := reflect.TypeOf(v)
-	if derefType(vt).Kind() != reflect.Struct {
+	if schema.DerefType(vt).Kind() == reflect.Interface && vt.Kind() == reflect.Pointer {
+		return ro.unmarshalInterface(derefValue(reflect.ValueOf(v)), m)
+	}
+
+	// first, it must be a struct since we'll be parsing the jsonapi struct tags
+	if schema.DerefType(vt).Kind() != reflect.Struct {
 		return &TypeError{Actual: vt.String(), Expected: []string{"struct"}}
 	}
 
 	rv := derefValue(reflect.ValueOf(v))
-	rt := reflect.TypeOf(rv.Interface())
-	if err := ro.unmarshalFields(v, rv, rt, m); err != nil {
+	if rv.CanAddr() && m.identities.claim(ro, rv.Addr()) {
+		return nil
+	}
+
+	// generated code unmarshals the resource object without inspecting the struct tags
+	if rv.CanAddr() {
+		if ru, ok := rv.Addr().Interface().(ResourceUnmarshaler); ok {
+			return ru.UnmarshalJSONAPIResource(&ResourceReader{v: v, ro: ro, m: m})
+		}
+	}
+
+	if err := ro.unmarshalFields(v, rv, m); err != nil {
 		return err
 	}
 
-	return ro.unmarshalAttributes(v)
+	return ro.unmarshalAttributes(v, m)
 }
 
 // unmarshalFields unmarshals a resource object into all non-attribute struct fields
-func (ro *resourceObject) unmarshalFields(v any, rv reflect.Value, rt reflect.Type, m *Unmarshaler) error {
+func (ro *resourceObject) unmarshalFields(v any, rv reflect.Value, m *Unmarshaler) error {
+	fields, err := schema.CachedTypeFields(rv.Type())
+	if err != nil {
+		return err
+	}
+
 	setPrimary := false
 
-	for i := 0; i < rv.NumField(); i++ {
-		fv := rv.Field(i)
-		ft := rt.Field(i)
+	for i := range fields {
+		ft := &fields[i]
+		jsonapiTag := ft.Tag
 
-		jsonapiTag, err := parseJSONAPITag(ft)
-		if err != nil {
-			return err
-		}
-		if jsonapiTag == nil {
-			if ft.Anonymous && fv.Kind() == reflect.Struct {
-				if err := ro.unmarshalFields(v, fv, reflect.TypeOf(fv.Interface()), m); err != nil {
-					return err
-				}
-			}
-			continue
-		}
-
-		switch jsonapiTag.directive {
-		case primary:
+		switch jsonapiTag.Directive {
+		case schema.Primary:
 			if setPrimary {
 				return ErrUnmarshalDuplicatePrimaryField
 			}
-			if ro.Type != jsonapiTag.resourceType {
-				return &TypeError{Actual: ro.Type, Expected: []string{jsonapiTag.resourceType}}
-			}
-			if !isValidMemberName(ro.Type, m.memberNameValidationMode) {
-				// type names count as member names
-				return &MemberNameValidationError{ro.Type}
+			if err := ro.checkType(jsonapiTag.ResourceType); err != nil {
+				return err
 			}
 
 			// if omitempty is allowed, skip if this is an empty id
-			if jsonapiTag.omitEmpty && ro.ID == "" {
-				continue
-			}
-
-			// to unmarshal the id we follow these rules
-			//     1. Use UnmarshalIdentifier if it is implemented
-			//     2. Use encoding.TextUnmarshaler if it is implemented
-			//     3. Use the value directly if it is a string
-			//     4. Fail
-			if vu, ok := v.(UnmarshalIdentifier); ok {
-				if err := vu.UnmarshalID(ro.ID); err != nil {
-					return err
-				}
-				setPrimary = true
-				continue
-			}
-
-			// get the underlying fields interface
-			var fvi any
-			switch fv.CanAddr() {
-			case true:
-				fvi = fv.Addr().Interface()
-			default:
-				fvi = fv.Interface()
-			}
-
-			if fviu, ok := fvi.(encoding.TextUnmarshaler); ok {
-				if err := fviu.UnmarshalText([]byte(ro.ID)); err != nil {
-					return err
-				}
-				setPrimary = true
+			if jsonapiTag.OmitEmpty && ro.ID == "" {
 				continue
 			}
 
-			if fv.Kind() == reflect.String {
-				fv.SetString(ro.ID)
-				setPrimary = true
-				continue
+			fv, _ := schema.FieldByIndex(rv, ft.Index, true)
+			if err := ro.unmarshalPrimary(v, fv); err != nil {
+				return err
 			}
-
-			return ErrUnmarshalInvalidPrimaryField
-		case relationship:
-			name, exported, _ := parseJSONTag(ft)
-			if !exported {
+			setPrimary = true
+		case schema.Relationship:
+			if !ft.Exported {
 				continue
 			}
-			relDocument, ok := ro.Relationships[name]
+			relDocument, ok := ro.Relationships[ft.MemberName]
 			if !ok {
 				continue
 			}
-			if !relDocument.hasMany && relDocument.isEmpty() {
-				// ensure struct field is nil for data:null cases only (we want empty slice for data:[])
-				if canBeNil(fv) {
-					fv.Set(reflect.Zero(ft.Type))
-				}
-				continue
-			}
-
-			rm := m.relationshipUnmarshaler()
-			rel := reflect.New(derefType(ft.Type)).Interface()
-			if err := relDocument.unmarshal(rel, rm); err != nil {
+			fv, _ := schema.FieldByIndex(rv, ft.Index, true)
+			if err := unmarshalRelationship(relDocument, fv, m); err != nil {
 				return err
 			}
-			setFieldValue(fv, rel)
-		case meta:
+		case schema.Meta:
 			if ro.Meta == nil {
 				continue
 			}
-			b, err := json.Marshal(ro.Meta)
-			if err != nil {
+			fv, _ := schema.FieldByIndex(rv, ft.Index, true)
+			if err := ro.unmarshalMeta(fv); err != nil {
 				return err
 			}
-
-			meta := reflect.New(derefType(ft.Type)).Interface()
-			if err = json.Unmarshal(b, meta); err != nil {
+		case schema.Presence:
+			fv, _ := schema.FieldByIndex(rv, ft.Index, true)
+			if err := ro.unmarshalPresence(fv); err != nil {
 				return err
 			}
-			setFieldValue(fv, meta)
 		default:
 			continue
 		}
@@ -327,14 +490,140 @@ func (ro *resourceObject) unmarshalFields(v any, rv reflect.Value, rt reflect.Ty
 	return nil
 }
 
-func (ro *resourceObject) unmarshalAttributes(v any) error {
-	if len(ro.Attributes) == 0 {
+// checkType checks that the resource object has the given resource type. Type names are validated
+// as member names when the document is parsed, see validateTypeNames.
+func (ro *resourceObject) checkType(resourceType string) error {
+	if ro.Type != resourceType {
+		return &TypeError{Actual: ro.Type, Expected: []string{resourceType}}
+	}
+	return nil
+}
+
+// unmarshalPrimary unmarshals the resource object id into v, whose primary field is fv.
+func (ro *resourceObject) unmarshalPrimary(v any, fv reflect.Value) error {
+	// to unmarshal the id we follow these rules
+	//     1. Use UnmarshalIdentifier if it is implemented
+	//     2. Use encoding.TextUnmarshaler if it is implemented
+	//     3. Use the value directly if it is a string
+	//     4. Fail
+	if vu, ok := v.(UnmarshalIdentifier); ok {
+		return vu.UnmarshalID(ro.ID)
+	}
+
+	// get the underlying fields interface
+	var fvi any
+	switch fv.CanAddr() {
+	case true:
+		fvi = fv.Addr().Interface()
+	default:
+		fvi = fv.Interface()
+	}
+
+	if fviu, ok := fvi.(encoding.TextUnmarshaler); ok {
+		return fviu.UnmarshalText([]byte(ro.ID))
+	}
+
+	if fv.Kind() == reflect.String {
+		fv.SetString(ro.ID)
+		return nil
+	}
+
+	return ErrUnmarshalInvalidPrimaryField
+}
+
+// unmarshalRelationship unmarshals the relationship document relDocument into the field fv.
+func unmarshalRelationship(relDocument *document, fv reflect.Value, m *Unmarshaler) error {
+	if !relDocument.hasMany && relDocument.isEmpty() {
+		// ensure struct field is nil for data:null cases only (we want empty slice for data:[])
+		if canBeNil(fv) {
+			fv.Set(reflect.Zero(fv.Type()))
+		}
 		return nil
 	}
 
-	b, err := json.Marshal(ro.Attributes)
+	rm := m.relationshipUnmarshaler()
+	if !relDocument.hasMany && fv.Kind() == reflect.Pointer {
+		// reuse the value the resource was already unmarshaled into, if identities are preserved
+		if p, ok := rm.identities.lookup(relDocument.DataOne, fv.Type()); ok {
+			fv.Set(p)
+			return relDocument.DataOne.unmarshal(p.Interface(), rm)
+		}
+	}
+	rel := reflect.New(schema.DerefType(fv.Type())).Interface()
+	if m.merge {
+		if relDocument.hasMany && fv.Kind() == reflect.Slice {
+			// the resources already held are reused by unmarshalResourceObjects
+			reflect.ValueOf(rel).Elem().Set(fv)
+		} else if rid, ok := existingIdentifier(fv); ok && !relDocument.hasMany && rid == relDocument.DataOne.getIdentifier() {
+			if target, ok := mergeTarget(fv, fv.Type()); ok {
+				rel = target
+			}
+		}
+	}
+	if err := relDocument.unmarshal(rel, rm); err != nil {
+		return err
+	}
+	setFieldValue(fv, rel)
+	return nil
+}
+
+// unmarshalMeta unmarshals the resource object meta into the field fv.
+func (ro *resourceObject) unmarshalMeta(fv reflect.Value) error {
+	b, err := rawMeta(ro.Meta)
 	if err != nil {
 		return err
 	}
+
+	meta := reflect.New(schema.DerefType(fv.Type())).Interface()
+	if err = json.Unmarshal(b, meta); err != nil {
+		return err
+	}
+	setFieldValue(fv, meta)
+	return nil
+}
+
+// unmarshalPresence sets the field fv, a map[string]bool, to the set of the member names of the
+// attributes and relationships of the resource object.
+func (ro *resourceObject) unmarshalPresence(fv reflect.Value) error {
+	ft := fv.Type()
+	if ft.Kind() != reflect.Map || ft.Key().Kind() != reflect.String || ft.Elem().Kind() != reflect.Bool {
+		return &TypeError{Actual: ft.String(), Expected: []string{"map[string]bool"}}
+	}
+
+	present := reflect.MakeMapWithSize(ft, len(ro.Relationships))
+	if len(ro.rawAttributes) > 0 {
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(ro.rawAttributes, &attributes); err != nil {
+			return err
+		}
+		for name := range attributes {
+			present.SetMapIndex(reflect.ValueOf(name).Convert(ft.Key()), reflect.ValueOf(true).Convert(ft.Elem()))
+		}
+	}
+	for name := range ro.Relationships {
+		present.SetMapIndex(reflect.ValueOf(name).Convert(ft.Key()), reflect.ValueOf(true).Convert(ft.Elem()))
+	}
+
+	fv.Set(present)
+	return nil
+}
+
+func (ro *resourceObject) unmarshalAttributes(v any, m *Unmarshaler) error {
+	b := ro.rawAttributes
+	if len(b) == 0 {
+		return nil
+	}
+
+	if m.merge {
+		if err := resetAttributes(b, derefValue(reflect.ValueOf(v))); err != nil {
+			return err
+		}
+	}
+
+	if m.disallowUnknownFields {
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		return dec.Decode(v)
+	}
 	return json.Unmarshal(b, v)
 }
@@ -66,3 +66,95 @@ func ExampleUnmarshalMeta() {
 	fmt.Printf("%s %s %+v %+v", a.ID, a.Title, a.Meta, m)
 	// Output: 1 Hello World &{Views:10} map[foo:bar]
 }
+
+func ExampleUnmarshal_errors() {
+	body := `{"errors":[{"status":"404","title":"Not Found","detail":"article 1 does not exist"}]}`
+
+	var errs []*jsonapi.Error
+	err := jsonapi.Unmarshal([]byte(body), &errs)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%d %s", *errs[0].Status, errs[0].Error())
+	// Output: 404 Not Found: article 1 does not exist
+}
+
+func ExampleNewUnmarshaler() {
+	body := `{"data":{"id":"1","type":"articles","attributes":{"title":"Hello World"}},"meta":{"request-id":"abc"}}`
+
+	type Article struct {
+		ID    string `jsonapi:"primary,articles"`
+		Title string `jsonapi:"attribute" json:"title"`
+	}
+
+	// created once and shared, values to decode into are given for each call
+	u := jsonapi.NewUnmarshaler(jsonapi.UnmarshalSetNameValidation(jsonapi.DisableValidation))
+
+	var (
+		a Article
+		m map[string]any
+	)
+	err := u.Unmarshal([]byte(body), &a, jsonapi.UnmarshalMeta(&m))
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s %s %+v", a.ID, a.Title, m)
+	// Output: 1 Hello World map[request-id:abc]
+}
+
+type Owner interface {
+	OwnerName() string
+}
+
+type Person struct {
+	ID   string `jsonapi:"primary,people"`
+	Name string `jsonapi:"attribute" json:"name"`
+}
+
+func (p *Person) OwnerName() string {
+	return p.Name
+}
+
+type Organization struct {
+	ID   string `jsonapi:"primary,organizations"`
+	Name string `jsonapi:"attribute" json:"name"`
+}
+
+func (o *Organization) OwnerName() string {
+	return o.Name
+}
+
+func ExampleUnmarshalRegisterType() {
+	body := `{"data":{"id":"1","type":"repositories","relationships":{"owner":{"data":{"id":"1","type":"organizations"}}}},"included":[{"id":"1","type":"organizations","attributes":{"name":"DataDog"}}]}`
+
+	type Repository struct {
+		ID    string `jsonapi:"primary,repositories"`
+		Owner Owner  `jsonapi:"relationship" json:"owner"`
+	}
+
+	var r Repository
+	err := jsonapi.Unmarshal([]byte(body), &r,
+		jsonapi.UnmarshalRegisterType("people", Person{}),
+		jsonapi.UnmarshalRegisterType("organizations", Organization{}),
+	)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%T %s", r.Owner, r.Owner.OwnerName())
+	// Output: *jsonapi_test.Organization DataDog
+}
+
+func ExampleUnmarshalPreserveIdentity() {
+	body := `{"data":{"id":"1","type":"articles","relationships":{"author":{"data":{"id":"1","type":"author"}},"comments":{"data":[{"id":"1","type":"comments"}]}}},"included":[{"id":"1","type":"author","attributes":{"name":"A"}},{"id":"1","type":"comments","attributes":{"comment":"Self-review"},"relationships":{"Author":{"data":{"id":"1","type":"author"}}}}]}`
+
+	var a Article
+	if err := jsonapi.Unmarshal([]byte(body), &a, jsonapi.UnmarshalPreserveIdentity()); err != nil {
+		panic(err)
+	}
+
+	fmt.Println(a.Author == a.Comments[0].Author)
+	// Output: true
+}
@@ -2,7 +2,9 @@ package jsonapi
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
 	"testing"
 
@@ -487,8 +489,7 @@ func TestUnmarshal(t *testing.T) {
 			expect:      &articlesRelatedComplex,
 			expectError: nil,
 		}, {
-			// TODO(#36): Add unmarshaling of errors as a supported use-case
-			description: "Errors don't unmarshal",
+			description: "Errors into *Article",
 			given:       errorsSimpleStructBody,
 			do: func(body []byte) (any, error) {
 				var a Article
@@ -496,7 +497,79 @@ func TestUnmarshal(t *testing.T) {
 				return &a, err
 			},
 			expect:      &Article{},
-			expectError: ErrErrorUnmarshalingNotImplemented,
+			expectError: &DocumentError{Errors: []*Error{&errorsSimpleStruct}},
+		}, {
+			description: "empty errors into *Article",
+			given:       `{"errors":[]}`,
+			do: func(body []byte) (any, error) {
+				var a Article
+				err := Unmarshal(body, &a)
+				return &a, err
+			},
+			expect:      &Article{},
+			expectError: &DocumentError{Errors: []*Error{}},
+		}, {
+			description: "empty errors into *Error",
+			given:       `{"errors":[]}`,
+			do: func(body []byte) (any, error) {
+				var e *Error
+				err := Unmarshal(body, &e)
+				return e, err
+			},
+			expect:      (*Error)(nil),
+			expectError: &DocumentError{Errors: []*Error{}},
+		}, {
+			description: "[]*Error",
+			given:       errorsComplexSliceManyBody,
+			do: func(body []byte) (any, error) {
+				var e []*Error
+				err := Unmarshal(body, &e)
+				return e, err
+			},
+			expect: []*Error{
+				&errorsSimpleStruct,
+				{
+					ID:     "1",
+					Links:  &ErrorLink{About: "A", Type: "TY"},
+					Status: Status(http.StatusInternalServerError),
+					Code:   "C",
+					Title:  "T",
+					Detail: "D",
+					Source: &ErrorSource{Pointer: "PO", Parameter: "PA", Header: "H"},
+					Meta:   map[string]any{"K": "V"},
+				},
+			},
+			expectError: nil,
+		}, {
+			description: "[]Error",
+			given:       errorsSimpleStructBody,
+			do: func(body []byte) (any, error) {
+				var e []Error
+				err := Unmarshal(body, &e)
+				return e, err
+			},
+			expect:      errorsSimpleSliceSingle,
+			expectError: nil,
+		}, {
+			description: "*Error",
+			given:       errorsSimpleStructBody,
+			do: func(body []byte) (any, error) {
+				var e *Error
+				err := Unmarshal(body, &e)
+				return e, err
+			},
+			expect:      &errorsSimpleStruct,
+			expectError: nil,
+		}, {
+			description: "Error",
+			given:       errorsSimpleStructBody,
+			do: func(body []byte) (any, error) {
+				var e Error
+				err := Unmarshal(body, &e)
+				return e, err
+			},
+			expect:      errorsSimpleStruct,
+			expectError: nil,
 		}, {
 			description: "CommentEmbedded",
 			given:       commentEmbeddedBody,
@@ -509,6 +582,19 @@ func TestUnmarshal(t *testing.T) {
 			expectError: nil,
 		},
 		{
+			description: "CommentEmbeddedRelationshipPointer",
+			given:       commentEmbeddedBody,
+			do: func(body []byte) (any, error) {
+				var a struct {
+					ID string `jsonapi:"primary,comments"`
+					*CommentFieldsPointer
+				}
+				err := Unmarshal(body, &a)
+				return a.CommentFieldsPointer, err
+			},
+			expect:      &commentEmbeddedFieldsPointer,
+			expectError: nil,
+		}, {
 			description: "CommentEmbeddedPointer",
 			given:       commentEmbeddedBody,
 			do: func(body []byte) (any, error) {
@@ -540,6 +626,16 @@ func TestUnmarshal(t *testing.T) {
 			},
 			expect:      &articleLinkedOnlySelf,
 			expectError: nil,
+		}, {
+			description: "*Node (recursive embedding)",
+			given:       nodeBody,
+			do: func(body []byte) (any, error) {
+				var n Node
+				err := Unmarshal(body, &n)
+				return &n, err
+			},
+			expect:      &Node{ID: "1", Label: Label{Text: "A"}},
+			expectError: nil,
 		},
 	}
 
@@ -561,6 +657,56 @@ func TestUnmarshal(t *testing.T) {
 	}
 }
 
+func TestUnmarshalNumberPrecision(t *testing.T) {
+	t.Parallel()
+
+	type counterMeta struct {
+		Total int64 `json:"total"`
+	}
+	type counter struct {
+		ID    string       `jsonapi:"primary,counters"`
+		Value int64        `jsonapi:"attribute" json:"value"`
+		Meta  *counterMeta `jsonapi:"meta"`
+	}
+
+	body := `{"data":{"id":"1","type":"counters","attributes":{"value":9007199254740993},"meta":{"total":9007199254740995}},"meta":{"total":9007199254740997}}`
+
+	var (
+		c counter
+		m counterMeta
+	)
+	err := Unmarshal([]byte(body), &c, UnmarshalMeta(&m))
+	is.MustNoError(t, err)
+	is.Equal(t, int64(9007199254740993), c.Value)
+	is.Equal(t, &counterMeta{Total: 9007199254740995}, c.Meta)
+	is.Equal(t, counterMeta{Total: 9007199254740997}, m)
+}
+
+func TestUnmarshalErrorDocument(t *testing.T) {
+	t.Parallel()
+
+	body := `{"errors":[{"status":"404","title":"Not Found"}],"meta":{"foo":"bar"},"links":{"self":"http://example.com/articles/1"},"jsonapi":{"version":"1.0"}}`
+
+	var (
+		a Article
+		m map[string]any
+		l Link
+	)
+	err := Unmarshal([]byte(body), &a, UnmarshalMeta(&m), UnmarshalLinks(&l))
+	is.MustError(t, err)
+
+	var de *DocumentError
+	is.MustEqual(t, true, errors.As(err, &de))
+	is.Equal(t, []*Error{{Status: Status(http.StatusNotFound), Title: "Not Found"}}, de.Errors)
+
+	var e *Error
+	is.MustEqual(t, true, errors.As(err, &e))
+	is.Equal(t, "Not Found", e.Title)
+
+	is.Equal(t, map[string]any{"foo": "bar"}, m)
+	is.Equal(t, Link{Self: "http://example.com/articles/1"}, l)
+}
+
 func TestUnmarshalMeta(t *testing.T) {
 	t.Parallel()
 
@@ -754,9 +900,253 @@ func TestUnmarshalLinks(t *testing.T) {
 	}
 }
 
+func TestUnmarshalIncluded(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		do          func() (any, error)
+		expect      any
+		expectError error
+	}{
+		{
+			description: "[]*Author",
+			do: func() (any, error) {
+				var (
+					a        []*ArticleRelated
+					included []*Author
+				)
+				err := Unmarshal([]byte(articleRelatedAuthorTwiceWithIncludeBody), &a, UnmarshalIncluded(&included))
+				return included, err
+			},
+			expect: []*Author{{ID: "1", Name: "A"}},
+		}, {
+			description: "[]*Comment with nested relationships",
+			do: func() (any, error) {
+				var (
+					a        ArticleRelated
+					included []*Comment
+				)
+				err := Unmarshal([]byte(articleRelatedCommentsWithIncludeBody), &a, UnmarshalIncluded(&included))
+				return included, err
+			},
+			expect: []*Comment{{ID: "1", Body: "A", Author: &Author{ID: "1"}}},
+		}, {
+			description: "map[string][]any",
+			do: func() (any, error) {
+				var (
+					a        ArticleRelated
+					included map[string][]any
+				)
+				err := Unmarshal([]byte(articleRelatedCompleteWithIncludeBody), &a,
+					UnmarshalIncluded(&included),
+					UnmarshalRegisterType("author", &Author{}),
+					UnmarshalRegisterType("comments", &Comment{}),
+				)
+				return included, err
+			},
+			expect: map[string][]any{
+				"author":   {&Author{ID: "1", Name: "A"}},
+				"comments": {&Comment{ID: "1", Body: "A"}, &Comment{ID: "2", Body: "B"}},
+			},
+		}, {
+			description: "no included resources",
+			do: func() (any, error) {
+				var (
+					a        ArticleRelated
+					included []*Author
+				)
+				err := Unmarshal([]byte(articleRelatedAuthorBody), &a, UnmarshalIncluded(&included))
+				return included, err
+			},
+			expect: ([]*Author)(nil),
+		}, {
+			description: "[]*Author with other types",
+			do: func() (any, error) {
+				var (
+					a        ArticleRelated
+					included []*Author
+				)
+				err := Unmarshal([]byte(articleRelatedCompleteWithIncludeBody), &a, UnmarshalIncluded(&included))
+				return included, err
+			},
+			expectError: &TypeError{Actual: "comments", Expected: []string{"author"}},
+		}, {
+			description: "[]any without registered types",
+			do: func() (any, error) {
+				var (
+					a        ArticleRelated
+					included []any
+				)
+				err := Unmarshal([]byte(articleRelatedCompleteWithIncludeBody), &a, UnmarshalIncluded(&included))
+				return included, err
+			},
+			expectError: &TypeError{Actual: "author", Expected: []string{"users"}},
+		}, {
+			description: "map with non-slice values",
+			do: func() (any, error) {
+				var (
+					a        ArticleRelated
+					included map[string]*Author
+				)
+				err := Unmarshal([]byte(articleRelatedCompleteWithIncludeBody), &a, UnmarshalIncluded(&included))
+				return included, err
+			},
+			expectError: &TypeError{Actual: "map[string]*jsonapi.Author", Expected: []string{"slice", "map[string]slice"}},
+		}, {
+			description: "not a pointer",
+			do: func() (any, error) {
+				var (
+					a        ArticleRelated
+					included []*Author
+				)
+				err := Unmarshal([]byte(articleRelatedCompleteWithIncludeBody), &a, UnmarshalIncluded(included))
+				return included, err
+			},
+			expectError: &TypeError{Actual: "slice", Expected: []string{"non-nil pointer"}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := tc.do()
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
+func TestUnmarshalPresence(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		description string
+		given       string
+		do          func(body []byte) (any, error)
+		expect      any
+		expectError error
+	}{
+		{
+			description: "attributes and relationships",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"title":"","body":null},"relationships":{"author":{"data":null}}}}`,
+			do: func(body []byte) (any, error) {
+				var a ArticleWithPresence
+				err := Unmarshal(body, &a)
+				return a.Present, err
+			},
+			expect: map[string]bool{"title": true, "body": true, "author": true},
+		}, {
+			description: "no members",
+			given:       `{"data":{"id":"1","type":"articles"}}`,
+			do: func(body []byte) (any, error) {
+				var a ArticleWithPresence
+				err := Unmarshal(body, &a)
+				return a.Present, err
+			},
+			expect: map[string]bool{},
+		}, {
+			description: "per resource",
+			given:       `{"data":[{"id":"1","type":"articles","attributes":{"title":"A"}},{"id":"2","type":"articles","relationships":{"author":{"data":{"id":"1","type":"author"}}}}]}`,
+			do: func(body []byte) (any, error) {
+				var a []ArticleWithPresence
+				err := Unmarshal(body, &a)
+				if err != nil {
+					return nil, err
+				}
+				return []map[string]bool{a[0].Present, a[1].Present}, nil
+			},
+			expect: []map[string]bool{{"title": true}, {"author": true}},
+		}, {
+			description: "invalid field type",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"title":"A"}}}`,
+			do: func(body []byte) (any, error) {
+				var a struct {
+					ID      string   `jsonapi:"primary,articles"`
+					Present []string `jsonapi:"presence" json:"-"`
+				}
+				err := Unmarshal(body, &a)
+				return a.Present, err
+			},
+			expectError: &TypeError{Actual: "[]string", Expected: []string{"map[string]bool"}},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			actual, err := tc.do([]byte(tc.given))
+			if tc.expectError != nil {
+				is.EqualError(t, tc.expectError, err)
+				return
+			}
+			is.MustNoError(t, err)
+			is.Equal(t, tc.expect, actual)
+		})
+	}
+}
+
 // TestUnmarshalMemberNameValidation collects tests which verify that invalid member names are
 // caught during unmarshaling, no matter where they're placed. This test does not exhaustively test
 // every possible invalid name.
+func TestUnmarshaler(t *testing.T) {
+	t.Parallel()
+
+	m := NewUnmarshaler(UnmarshalSetNameValidation(DisableValidation))
+
+	tests := []struct {
+		description string
+		given       string
+		opts        []UnmarshalOption
+		expect      Link
+		expectError error
+	}{
+		{
+			description: "options given to NewUnmarshaler",
+			given:       `{"data":{"id":"1","type":"art%icles","attributes":{"title":"A"}}}`,
+			expectError: &TypeError{Actual: "art%icles", Expected: []string{"articles"}},
+		}, {
+			description: "per-call options are added",
+			given:       articleAWithTopLevelLinksRelated,
+			expect:      Link{Related: map[string]any{"href": "http://example.com/article/1/comments", "meta": map[string]any{"foo": "bar"}}},
+		}, {
+			description: "per-call options override",
+			given:       `{"data":{"id":"1","type":"articles","attributes":{"title":"A"}},"meta":{"foo%":1}}`,
+			opts:        []UnmarshalOption{UnmarshalSetNameValidation(DefaultValidation)},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/meta/foo%"},
+		},
+	}
+
+	for i, tc := range tests {
+		tc := tc
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			t.Parallel()
+			t.Log(tc.description)
+
+			// the subtests run in parallel with the same Unmarshaler
+			for j := 0; j < 10; j++ {
+				var (
+					a Article
+					l Link
+				)
+				err := m.Unmarshal([]byte(tc.given), &a, append(tc.opts, UnmarshalLinks(&l))...)
+				is.EqualError(t, tc.expectError, err)
+				is.Equal(t, tc.expect, l)
+			}
+		})
+	}
+}
+
 func TestUnmarshalMemberNameValidation(t *testing.T) {
 	t.Parallel()
 
@@ -788,7 +1178,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/data/type"},
 		}, {
 			description: "Author with invalid attribute member name",
 			given:       authorWithInvalidAttributeNameBody,
@@ -797,7 +1187,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"na%me"},
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/data/attributes/na%me"},
 		}, {
 			description: "Article with invalid resource meta member name",
 			given:       articleWithInvalidResourceMetaMemberNameBody,
@@ -806,7 +1196,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/data/meta/foo%"},
 		}, {
 			description: "Article with invalid top-level meta member name",
 			given:       articleWithInvalidToplevelMetaMemberNameBody,
@@ -819,7 +1209,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/meta/foo%"},
 		}, {
 			description: "Article with invalid link meta member name",
 			given:       articleWithInvalidLinkMetaMemberNameBody,
@@ -828,7 +1218,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/data/links/self/meta/foo%"},
 		}, {
 			description: "Article with invalid jsonapi meta member name",
 			given:       articleWithInvalidJSONAPIMetaMemberNameBody,
@@ -837,7 +1227,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/jsonapi/meta/foo%"},
 		}, {
 			description: "Article with invalid relationship name",
 			given:       articleWithInvalidRelationshipNameBody,
@@ -846,7 +1236,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/data/relationships/aut%hor"},
 		}, {
 			description: "Article with invalid relationship type name body",
 			given:       articleWithInvalidRelationshipTypeNameBody,
@@ -855,7 +1245,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/data/relationships/author/data/type"},
 		}, {
 			description: "Article with invalid relationship attribute member names not included",
 			given:       articleWithInvalidRelationshipAttributeNameNotIncludedBody,
@@ -873,7 +1263,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"na%me"},
+			expectError: &MemberNameValidationError{MemberName: "na%me", Path: "/included/0/attributes/na%me"},
 		}, {
 			description: "[]*Article with one invalid resource meta member name",
 			given:       articlesWithOneInvalidResourceMetaMemberName,
@@ -882,7 +1272,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"foo%"},
+			expectError: &MemberNameValidationError{MemberName: "foo%", Path: "/data/1/meta/foo%"},
 		}, {
 			description: "Website with invalid nested relationship type member name",
 			given:       websiteWithInvalidNestedRelationshipTypeNameBody,
@@ -891,7 +1281,7 @@ func TestUnmarshalMemberNameValidation(t *testing.T) {
 				err := Unmarshal(body, &a, opts...)
 				return err
 			},
-			expectError: &MemberNameValidationError{"aut%hor"},
+			expectError: &MemberNameValidationError{MemberName: "aut%hor", Path: "/included/1/relationships/author/data/type"},
 		},
 	}
 
@@ -947,6 +1337,11 @@ func BenchmarkUnmarshal(b *testing.B) {
 			data:   articlesRelatedComplexBody,
 			target: []*ArticleRelated{},
 			opts:   []UnmarshalOption{UnmarshalSetNameValidation(DisableValidation)},
+		}, {
+			name:   "ArticlesLarge",
+			data:   string(mustMarshal(articlesLarge)),
+			target: []*ArticleRelated{},
+			opts:   nil,
 		},
 	}
 
@@ -961,3 +1356,11 @@ func BenchmarkUnmarshal(b *testing.B) {
 		})
 	}
 }
+
+func mustMarshal(v any, opts ...MarshalOption) []byte {
+	b, err := Marshal(v, opts...)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}